@@ -0,0 +1,55 @@
+package gonf
+
+import (
+	"os"
+	"strings"
+)
+
+// Remember a setting registered after the first Load while the lock is held,
+// so that the next Reload reads its environment variable and options.
+func (c *Config) late(s setting) {
+	if c.started {
+		c.added = append(c.added, s)
+	}
+}
+
+// Parse the environment variables and command line options of the settings
+// registered since the last Load or Reload, with options taking precedence,
+// and record where each value came from.
+func (c *Config) parseAdded() map[string]interface{} {
+	c.mu.Lock()
+	added, empty := c.added, c.clearEmpty
+	c.added = nil
+	c.mu.Unlock()
+	if len(added) == 0 {
+		return nil
+	}
+	envs, opts := map[string]interface{}{}, map[string]interface{}{}
+	sources := map[string]map[string]string{"env": {}, "option": {}}
+	c.parseSettingEnvs(added, empty, envs, sources["env"])
+	for i := 0; i < len(os.Args); i++ {
+		if arg := os.Args[i]; arg == "--" {
+			break
+		} else if len(arg) == 1 || !strings.HasPrefix(arg, "-") {
+			continue
+		} else if strings.HasPrefix(arg, "--") {
+			c.parseLong(&i, added, opts, sources["option"])
+		} else {
+			c.parseShort(&i, added, opts, sources["option"])
+		}
+	}
+	c.mu.Lock()
+	if c.sources == nil {
+		c.sources = map[string]map[string]string{}
+	}
+	for layer, origins := range sources {
+		if c.sources[layer] == nil {
+			c.sources[layer] = map[string]string{}
+		}
+		for k, o := range origins {
+			c.sources[layer][k] = o
+		}
+	}
+	c.mu.Unlock()
+	return c.merge(envs, opts)
+}
@@ -0,0 +1,77 @@
+package gonf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddAfterLoad(t *testing.T) {
+	os.Args = []string{"app", "--plugin-name", "option"}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	os.Setenv("PLUGIN_PORT", "8080")
+	modTime := time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(`{"OptionString": "file"}`), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Load("/tmp/app.json"); e != nil {
+		t.Fatalf("failed to load, %v...", e)
+	}
+
+	// test registration is safe alongside loads and reloads
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) { defer wg.Done(); c.Add(fmt.Sprintf("plugin%d", i), "", "", fmt.Sprintf("--plugin%d", i)) }(i)
+		go func() { defer wg.Done(); c.Reload() }()
+	}
+	wg.Wait()
+
+	// test settings added after load are read by the next reload
+	c.Add("plugin.port", "", "PLUGIN_PORT")
+	c.Add("plugin.name", "", "", "--plugin-name")
+	if e := c.Reload(); e != nil {
+		t.Errorf("failed to reload added settings, %v...", e)
+	}
+	if v, _ := c.Get("plugin.port"); v != "8080" {
+		t.Errorf("failed to read environment variable of added setting, %v...", v)
+	} else if v, _ := c.Get("plugin.name"); v != "option" {
+		t.Errorf("failed to read option of added setting, %v...", v)
+	} else if s := c.source("plugin.name"); s != "option --plugin-name" {
+		t.Errorf("failed to record source of added setting, %s...", s)
+	} else if mc.OptionString != "file" {
+		t.Errorf("failed to keep loaded configuration, %s...", mc.OptionString)
+	}
+	if e := c.Reload(); e != errNoChanges {
+		t.Errorf("failed to read added settings only once, %v...", e)
+	}
+}
+
+func TestAddDuringLoad(t *testing.T) {
+	os.Args = []string{"app", "--port", "80"}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Unix(1, 0)}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(`{}`), nil }
+
+	var m struct {
+		Port int `json:"port" gonf:"flag=--port,env=APP_PORT"`
+	}
+	c := &Config{}
+	c.Target(&m)
+	reloads := 0
+	c.OnReload(func(ChangeSet) { reloads++ })
+	if e := c.Load("/tmp/app.json"); e != nil || m.Port != 80 {
+		t.Fatalf("failed to load tagged settings, %v %+v...", e, m)
+	}
+	if e := c.Reload(); e != errNoChanges || reloads != 0 {
+		t.Errorf("failed to skip settings registered during load, %v %d...", e, reloads)
+	}
+}
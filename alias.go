@@ -0,0 +1,63 @@
+package gonf
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// An old dot-notation key that is renamed to its replacement when read.
+type alias struct {
+	old, name string
+}
+
+// Move values supplied under an alias to the name that replaced it, unless
+// the name was also supplied, and log the deprecation.
+func (c *Config) migrate(vars map[string]interface{}) map[string]interface{} {
+	c.mu.RLock()
+	aliases := append([]alias(nil), c.aliases...)
+	c.mu.RUnlock()
+	for _, a := range aliases {
+		v, ok := c.lookup(vars, a.old)
+		if !ok {
+			continue
+		}
+		c.unset(vars, a.old)
+		for p := a.old; strings.Contains(p, "."); {
+			p = p[:strings.LastIndex(p, ".")]
+			m, _ := c.lookup(vars, p)
+			if mm, ok := m.(map[string]interface{}); !ok {
+				break
+			} else if len(mm) == 0 {
+				c.unset(vars, p)
+			}
+		}
+		if _, exists := c.lookup(vars, a.name); !exists {
+			c.set(vars, a.name, v)
+		}
+		if c.event(slog.LevelInfo, "deprecated configuration used", slog.String("used", a.old), slog.String("replacement", a.name)) {
+			continue
+		} else if l := c.logger(); l != nil {
+			l.Info("%s is deprecated, use %s instead", a.old, a.name)
+		}
+	}
+	return vars
+}
+
+// Register an old dot-notation key as an alias of its replacement (eg.
+// `db.host` for `database.host`), so configuration files written before a
+// rename continue to work.  Using the alias logs a deprecation notice naming
+// the replacement, which takes precedence when both are supplied.
+func (c *Config) Alias(old, name string) error {
+	for _, n := range []string{old, name} {
+		if n == "" || strings.HasPrefix(n, ".") || strings.HasSuffix(n, ".") || strings.Contains(n, "..") {
+			return errBadNameSyntax
+		}
+	}
+	if old == name {
+		return errConflictingAdd
+	}
+	c.mu.Lock()
+	c.aliases = append(c.aliases, alias{old, name})
+	c.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,58 @@
+package gonf
+
+import (
+	"os"
+	"testing"
+)
+
+type mockAlias struct {
+	mockLogger
+	Database struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"database"`
+}
+
+func TestAlias(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	data := `{"db": {"host": "old", "port": 5432}}`
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	ma := &mockAlias{}
+	c := &Config{}
+	c.Target(ma)
+	if c.Alias("db..host", "database.host") == nil || c.Alias("db", "db") == nil {
+		t.Error("failed to reject bad aliases...")
+	}
+	c.Alias("db.host", "database.host")
+	c.Alias("db.port", "database.port")
+	c.Strict(true)
+
+	// test migrated keys with a deprecation notice
+	if e := c.Load("/tmp/app.json"); e != nil || ma.Database.Host != "old" || ma.Database.Port != 5432 || ma.infos != 2 {
+		t.Errorf("failed to migrate aliased keys, %v %+v...", e, ma)
+	}
+
+	// test replacement takes precedence
+	data = `{"db": {"host": "old"}, "database": {"host": "new"}}`
+	if e := c.Load("/tmp/app.json"); e != nil || ma.Database.Host != "new" {
+		t.Errorf("failed to prefer the replacement, %v %+v...", e, ma)
+	}
+
+	// test set through an alias
+	if e := c.Set("db.port", 3306); e != nil || ma.Database.Port != 3306 {
+		t.Errorf("failed to set through an alias, %v...", e)
+	}
+
+	// test aliases within and over list elements
+	data = `{"servers": [{"host": "listed"}], "db": [{"port": 1}]}`
+	c.Strict(false)
+	c.Alias("servers.0.host", "database.host")
+	c.Alias("db.0.port", "database.port")
+	if e := c.Load("/tmp/app.json"); e != nil || ma.Database.Host != "listed" || ma.Database.Port != 1 {
+		t.Errorf("failed to migrate aliased list elements, %v %+v...", e, ma)
+	}
+}
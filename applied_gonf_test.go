@@ -0,0 +1,163 @@
+// Code generated by gonfgen; DO NOT EDIT.
+
+package gonf_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cdelorme/gonf"
+)
+
+// ApplyConfig applies a merged configuration to Applied without reflection.
+func (t *Applied) ApplyConfig(m map[string]interface{}) error {
+	var errs []error
+	for k0, v0 := range m {
+		switch strings.ToLower(k0) {
+		case "level":
+			if v0 == nil {
+				gonf.Zero(&t.Embedded.Level)
+			} else if r, err := gonf.ToInt(v0, 0); err != nil {
+				errs = append(errs, fmt.Errorf("level: %w", err))
+			} else {
+				t.Embedded.Level = int(r)
+			}
+		case "name":
+			if v0 == nil {
+				gonf.Zero(&t.Name)
+			} else if r, err := gonf.ToString(v0); err != nil {
+				errs = append(errs, fmt.Errorf("name: %w", err))
+			} else {
+				t.Name = string(r)
+			}
+		case "port":
+			if v0 == nil {
+				gonf.Zero(&t.Port)
+			} else if r, err := gonf.ToInt(v0, 0); err != nil {
+				errs = append(errs, fmt.Errorf("port: %w", err))
+			} else {
+				t.Port = int(r)
+			}
+		case "enabled":
+			if v0 == nil {
+				gonf.Zero(&t.Enabled)
+			} else if r, err := gonf.ToBool(v0); err != nil {
+				errs = append(errs, fmt.Errorf("enabled: %w", err))
+			} else {
+				t.Enabled = bool(r)
+			}
+		case "hosts":
+			if v0 == nil {
+				gonf.Zero(&t.Hosts)
+			} else if l0, ok := v0.([]interface{}); ok {
+				t.Hosts = make([]string, len(l0))
+				for i0, e0 := range l0 {
+					if r, err := gonf.ToString(e0); err != nil {
+						errs = append(errs, fmt.Errorf("hosts: %w", err))
+					} else {
+						t.Hosts[i0] = string(r)
+					}
+				}
+			} else if err := gonf.Decode(v0, &t.Hosts); err != nil {
+				errs = append(errs, fmt.Errorf("hosts: %w", err))
+			}
+		case "ports":
+			if v0 == nil {
+				gonf.Zero(&t.Ports)
+			} else if l0, ok := v0.([]interface{}); ok {
+				t.Ports = make([]int, len(l0))
+				for i0, e0 := range l0 {
+					if r, err := gonf.ToInt(e0, 0); err != nil {
+						errs = append(errs, fmt.Errorf("ports: %w", err))
+					} else {
+						t.Ports[i0] = int(r)
+					}
+				}
+			} else if err := gonf.Decode(v0, &t.Ports); err != nil {
+				errs = append(errs, fmt.Errorf("ports: %w", err))
+			}
+		case "labels":
+			if v0 == nil {
+				gonf.Zero(&t.Labels)
+			} else if err := gonf.Decode(v0, &t.Labels); err != nil {
+				errs = append(errs, fmt.Errorf("labels: %w", err))
+			}
+		case "timeout":
+			if v0 == nil {
+				gonf.Zero(&t.Timeout)
+			} else if err := gonf.Decode(v0, &t.Timeout); err != nil {
+				errs = append(errs, fmt.Errorf("timeout: %w", err))
+			}
+		case "limits":
+			if v0 == nil {
+				gonf.Zero(&t.Limits)
+			} else if m0, ok := v0.(map[string]interface{}); ok {
+				if err := t.Limits.ApplyConfig(m0); err != nil {
+					errs = append(errs, err)
+				}
+			} else {
+				errs = append(errs, fmt.Errorf("limits: %w %v to object", gonf.ErrCast, v0))
+			}
+		case "backup":
+			if v0 == nil {
+				gonf.Zero(&t.Backup)
+			} else if m0, ok := v0.(map[string]interface{}); ok {
+				if t.Backup == nil {
+					t.Backup = new(Limits)
+				}
+				if err := t.Backup.ApplyConfig(m0); err != nil {
+					errs = append(errs, err)
+				}
+			} else {
+				errs = append(errs, fmt.Errorf("backup: %w %v to object", gonf.ErrCast, v0))
+			}
+		case "nested":
+			if v0 == nil {
+				gonf.Zero(&t.Nested)
+			} else if m0, ok := v0.(map[string]interface{}); ok {
+				for k1, v1 := range m0 {
+					switch strings.ToLower(k1) {
+					case "depth":
+						if v1 == nil {
+							gonf.Zero(&t.Nested.Depth)
+						} else if r, err := gonf.ToInt(v1, 64); err != nil {
+							errs = append(errs, fmt.Errorf("nested.depth: %w", err))
+						} else {
+							t.Nested.Depth = int64(r)
+						}
+					}
+				}
+			} else {
+				errs = append(errs, fmt.Errorf("nested: %w %v to object", gonf.ErrCast, v0))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyConfig applies a merged configuration to Limits without reflection.
+func (t *Limits) ApplyConfig(m map[string]interface{}) error {
+	var errs []error
+	for k0, v0 := range m {
+		switch strings.ToLower(k0) {
+		case "max":
+			if v0 == nil {
+				gonf.Zero(&t.Max)
+			} else if r, err := gonf.ToUint(v0, 8); err != nil {
+				errs = append(errs, fmt.Errorf("max: %w", err))
+			} else {
+				t.Max = uint8(r)
+			}
+		case "ratio":
+			if v0 == nil {
+				gonf.Zero(&t.Ratio)
+			} else if r, err := gonf.ToFloat(v0, 32); err != nil {
+				errs = append(errs, fmt.Errorf("ratio: %w", err))
+			} else {
+				t.Ratio = float32(r)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
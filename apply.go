@@ -0,0 +1,138 @@
+package gonf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Implemented by targets that apply a merged configuration themselves, such
+// as those with an ApplyConfig function generated by cmd/gonfgen, so that Load,
+// Reload, and Apply avoid reflection and json round-trips.  The map uses the
+// json names of each field, a nil value resets the field to its zero value,
+// and values may be strings from the command line or environment.
+type Applier interface {
+	ApplyConfig(map[string]interface{}) error
+}
+
+// Convert a configuration value to a bool, for generated code.
+func ToBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		return strconv.ParseBool(t)
+	}
+	return false, fmt.Errorf("%w %v to bool", ErrCast, v)
+}
+
+// Convert a configuration value to a signed integer of the bit size, for
+// generated code.
+func ToInt(v interface{}, bits int) (int64, error) {
+	var s string
+	switch t := v.(type) {
+	case int:
+		s = strconv.Itoa(t)
+	case int64:
+		s = strconv.FormatInt(t, 10)
+	case uint64:
+		s = strconv.FormatUint(t, 10)
+	case float64:
+		if t != float64(int64(t)) {
+			return 0, fmt.Errorf("%w %v to int", ErrCast, v)
+		}
+		s = strconv.FormatInt(int64(t), 10)
+	case json.Number:
+		s = t.String()
+	case string:
+		s = t
+	default:
+		return 0, fmt.Errorf("%w %v to int", ErrCast, v)
+	}
+	n, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q to int: %v", ErrCast, s, err)
+	}
+	return n, nil
+}
+
+// Convert a configuration value to an unsigned integer of the bit size, for
+// generated code.
+func ToUint(v interface{}, bits int) (uint64, error) {
+	var s string
+	switch t := v.(type) {
+	case int:
+		s = strconv.Itoa(t)
+	case int64:
+		s = strconv.FormatInt(t, 10)
+	case uint64:
+		s = strconv.FormatUint(t, 10)
+	case float64:
+		if t < 0 || t != float64(uint64(t)) {
+			return 0, fmt.Errorf("%w %v to uint", ErrCast, v)
+		}
+		s = strconv.FormatUint(uint64(t), 10)
+	case json.Number:
+		s = t.String()
+	case string:
+		s = t
+	default:
+		return 0, fmt.Errorf("%w %v to uint", ErrCast, v)
+	}
+	n, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q to uint: %v", ErrCast, s, err)
+	}
+	return n, nil
+}
+
+// Convert a configuration value to a float of the bit size, for generated
+// code.
+func ToFloat(v interface{}, bits int) (float64, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case int:
+		return float64(t), nil
+	case int64:
+		return float64(t), nil
+	case uint64:
+		return float64(t), nil
+	case json.Number:
+		return strconv.ParseFloat(t.String(), bits)
+	case string:
+		n, err := strconv.ParseFloat(t, bits)
+		if err != nil {
+			return 0, fmt.Errorf("%w %q to float: %v", ErrCast, t, err)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("%w %v to float", ErrCast, v)
+}
+
+// Convert a configuration value to a string, for generated code.
+func ToString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("%w %v to string", ErrCast, v)
+}
+
+// Decode a configuration value into a pointer through json, for generated
+// code dealing with types it does not handle directly.
+func Decode(v interface{}, dst interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("%w %v", ErrCast, err)
+	}
+	return nil
+}
+
+// Reset a field to its zero value, for generated code.
+func Zero[T any](p *T) {
+	var zero T
+	*p = zero
+}
@@ -0,0 +1,106 @@
+package gonf_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cdelorme/gonf"
+)
+
+// The ApplyConfig functions in applied_gonf_test.go are generated by
+// cmd/gonfgen from the types below, and checked by its tests.
+type Limits struct {
+	Max   uint8   `json:"max"`
+	Ratio float32 `json:"ratio"`
+}
+
+type Embedded struct {
+	Level int `json:"level"`
+}
+
+type Applied struct {
+	sync.Mutex
+	Embedded
+	Name    string            `json:"name"`
+	Port    int               `json:"port"`
+	Enabled bool              `json:"enabled"`
+	Hosts   []string          `json:"hosts"`
+	Ports   []int             `json:"ports"`
+	Labels  map[string]string `json:"labels"`
+	Timeout time.Duration     `json:"timeout"`
+	Limits  Limits            `json:"limits"`
+	Backup  *Limits           `json:"backup"`
+	Nested  struct {
+		Depth int64 `json:"depth"`
+	} `json:"nested"`
+	Skipped string `json:"-"`
+	hidden  string
+}
+
+func TestApplier(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Args = []string{"app", "--port", "8080", "--ports", "1", "--ports", "2"}
+	os.Setenv("APP_ENABLED", "true")
+
+	a := &Applied{Name: "default", Skipped: "kept"}
+	var _ gonf.Applier = a
+	c := &gonf.Config{}
+	c.Target(a)
+	c.Add("port", "", "", "--port")
+	c.Add("ports", "", "", "--ports")
+	c.Add("enabled", "", "APP_ENABLED")
+	if e := c.Load("/tmp/gonf-applier-missing.json"); e != nil {
+		t.Errorf("failed to load applier, %v...", e)
+	}
+	if a.Port != 8080 || !a.Enabled || len(a.Ports) != 2 || a.Ports[1] != 2 || a.Name != "default" {
+		t.Errorf("failed to apply converted values, %+v...", a)
+	}
+
+	if e := c.Apply(map[string]interface{}{
+		"name":    nil,
+		"Level":   3.0,
+		"hosts":   []interface{}{"a", "b"},
+		"labels":  map[string]interface{}{"k": "v"},
+		"timeout": 5e9,
+		"limits":  map[string]interface{}{"max": "7", "ratio": 0.5},
+		"backup":  map[string]interface{}{"max": 1.0},
+		"nested":  map[string]interface{}{"depth": "9"},
+		"Skipped": "changed",
+	}); e != nil {
+		t.Errorf("failed to apply overrides, %v...", e)
+	}
+	if a.Name != "" || a.Level != 3 || len(a.Hosts) != 2 || a.Labels["k"] != "v" || a.Timeout != 5*time.Second || a.Limits.Max != 7 || a.Limits.Ratio != 0.5 || a.Backup == nil || a.Backup.Max != 1 || a.Nested.Depth != 9 || a.Skipped != "kept" {
+		t.Errorf("failed to apply overrides, %+v...", a)
+	}
+
+	if e := c.Apply(map[string]interface{}{"port": "abc", "limits": map[string]interface{}{"max": 300.0}}); e == nil {
+		t.Error("failed to report conversion errors...")
+	}
+}
+
+func TestConversions(t *testing.T) {
+	for _, v := range []interface{}{7, int64(7), uint64(7), 7.0, json.Number("7"), "7"} {
+		if n, e := gonf.ToInt(v, 64); e != nil || n != 7 {
+			t.Errorf("failed to convert %T to int, %v %v...", v, n, e)
+		}
+		if n, e := gonf.ToUint(v, 64); e != nil || n != 7 {
+			t.Errorf("failed to convert %T to uint, %v %v...", v, n, e)
+		}
+		if n, e := gonf.ToFloat(v, 64); e != nil || n != 7 {
+			t.Errorf("failed to convert %T to float, %v %v...", v, n, e)
+		}
+	}
+	for _, v := range []interface{}{int64(-1), -1.0, "-1", true} {
+		if _, e := gonf.ToUint(v, 64); !errors.Is(e, gonf.ErrCast) {
+			t.Errorf("failed to reject %T %v as uint, %v...", v, v, e)
+		}
+	}
+	if _, e := gonf.ToInt(int64(300), 8); !errors.Is(e, gonf.ErrCast) {
+		t.Errorf("failed to reject overflow, %v...", e)
+	}
+}
@@ -0,0 +1,76 @@
+package gonf
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+var errUnknownArchive = errors.New("unsupported archive, expected zip, tar, or gzip compressed tar...")
+
+// Convert a tar archive into an in-memory zip, which implements fs.FS.
+func (c *Config) untar(data []byte) (fs.FS, error) {
+	tr := tar.NewReader(bytes.NewReader(data))
+	b := &bytes.Buffer{}
+	zw := zip.NewWriter(b)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w %v", errUnknownArchive, err)
+		} else if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: path.Clean(strings.TrimPrefix(h.Name, "/")), Method: zip.Store, Modified: h.ModTime})
+		if err != nil {
+			return nil, err
+		} else if _, err := io.Copy(w, tr); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+}
+
+func (c *Config) unpack(data []byte) (fs.FS, error) {
+	if len(data) > 1 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		} else if data, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+	if z, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		return z, nil
+	}
+	return c.untar(data)
+}
+
+// Read configuration files from a zip, tar, or gzip compressed tar archive
+// instead of the operating system, the same as FS, for sealed appliance
+// deployments.  Since a zip is read from its end, one appended to the
+// executable (eg. `cat app bundle.zip > sealed && zip -A sealed`) may be used
+// by passing the path from os.Executable.
+func (c *Config) Archive(name string) error {
+	data, err := readfile(name)
+	if err != nil {
+		return err
+	}
+	fsys, err := c.unpack(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	c.FS(fsys)
+	return nil
+}
@@ -0,0 +1,64 @@
+package gonf
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	file := []byte(`{"OptionString": "archived"}`)
+
+	z := &bytes.Buffer{}
+	zw := zip.NewWriter(z)
+	w, _ := zw.Create("app/gonf.json")
+	w.Write(file)
+	zw.Close()
+	tb := &bytes.Buffer{}
+	tw := tar.NewWriter(tb)
+	tw.WriteHeader(&tar.Header{Name: "app/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "app/gonf.json", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(file)), ModTime: time.Now()})
+	tw.Write(file)
+	tw.Close()
+	gz := &bytes.Buffer{}
+	gw := gzip.NewWriter(gz)
+	gw.Write(tb.Bytes())
+	gw.Close()
+
+	archives := map[string][]byte{
+		"zip":      z.Bytes(),
+		"tar":      tb.Bytes(),
+		"tar.gz":   gz.Bytes(),
+		"appended": append([]byte("\x7fELF executable"), z.Bytes()...),
+	}
+	for name, data := range archives {
+		readfile = func(string) ([]byte, error) { return data, nil }
+		mc := &mockConfig{}
+		c := &Config{}
+		c.Target(mc)
+		c.SetPaths("/")
+		if e := c.Archive("/tmp/bundle"); e != nil {
+			t.Errorf("failed to open %s archive, %v...", name, e)
+		} else if e := c.Load("app/gonf.json"); e != nil || mc.OptionString != "archived" {
+			t.Errorf("failed to load from %s archive, %v %s...", name, e, mc.OptionString)
+		}
+	}
+
+	readfile = func(string) ([]byte, error) { return []byte("not an archive"), nil }
+	if e := (&Config{}).Archive("/tmp/bundle"); !errors.Is(e, errUnknownArchive) {
+		t.Errorf("failed to reject unknown archive, %v...", e)
+	}
+	readfile = func(string) ([]byte, error) { return nil, os.ErrNotExist }
+	if e := (&Config{}).Archive("/tmp/bundle"); !os.IsNotExist(e) {
+		t.Errorf("failed to report missing archive, %v...", e)
+	}
+}
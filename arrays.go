@@ -0,0 +1,79 @@
+package gonf
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Parse a key as a canonical array index, such as the `0` in `servers.0.host`.
+func (c *Config) position(k string) (int, bool) {
+	i, err := strconv.Atoi(k)
+	return i, err == nil && i >= 0 && k == strconv.Itoa(i)
+}
+
+// Report whether every key of a map addresses an array element, and return the
+// indexes in ascending order.
+func (c *Config) indexes(m map[string]interface{}) ([]int, bool) {
+	var is []int
+	for k := range m {
+		i, ok := c.position(k)
+		if !ok {
+			return nil, false
+		}
+		is = append(is, i)
+	}
+	sort.Ints(is)
+	return is, len(is) > 0
+}
+
+// Copy a list with the elements addressed by index replaced, or merged when
+// both are maps, so that a key such as `servers.0.host` from the environment
+// or command line patches an element instead of replacing the whole list.  An
+// index one past the end appends, and indexes beyond that are ignored.
+func (c *Config) patch(l []interface{}, m map[string]interface{}) []interface{} {
+	is, _ := c.indexes(m)
+	out := append([]interface{}{}, l...)
+	for _, i := range is {
+		v := m[strconv.Itoa(i)]
+		if i > len(out) {
+			break
+		} else if i == len(out) {
+			out = append(out, nil)
+		}
+		out[i] = c.element(out[i], v)
+	}
+	return out
+}
+
+// Combine an existing value with the value patching it.
+func (c *Config) element(old, v interface{}) interface{} {
+	n, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	} else if m, is := old.(map[string]interface{}); is {
+		return c.merge(m, n)
+	} else if l, is := old.([]interface{}); is {
+		if _, indexed := c.indexes(n); indexed {
+			return c.patch(l, n)
+		}
+	}
+	return v
+}
+
+// Populate the elements of a slice or array addressed by index while the lock
+// is held, leaving the others untouched.
+func (c *Config) populateIndexes(d reflect.Value, m map[string]interface{}, path string) []error {
+	is, _ := c.indexes(m)
+	var errs []error
+	for _, i := range is {
+		if i == d.Len() && d.Kind() == reflect.Slice {
+			d.Set(reflect.Append(d, reflect.Zero(d.Type().Elem())))
+		} else if i >= d.Len() {
+			break
+		}
+		k := strconv.Itoa(i)
+		errs = append(errs, c.populate(d.Index(i), m[k], c.join(path, k))...)
+	}
+	return errs
+}
@@ -0,0 +1,64 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type mockHost struct {
+	Host string
+	Port int
+}
+
+type mockHosts struct {
+	Servers []mockHost
+	Ports   [2]int
+}
+
+func TestArrays(t *testing.T) {
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{}, nil }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"Servers": [{"Host": "a", "Port": 1}, {"Host": "b", "Port": 2}], "Ports": [1, 2]}`), nil
+	}
+	os.Args = []string{"app", "--set", "Servers.1.Port=9000", "--set", "Servers.2.Host=c", "--set", "Servers.9.Host=ignored", "--set", "Ports.1=3"}
+
+	m := &mockHosts{}
+	c := &Config{}
+	c.Target(m)
+	c.Add("Servers.0.Host", "", "PRIMARY_HOST")
+	os.Setenv("PRIMARY_HOST", "env")
+	if e := c.Load("/tmp/app.json"); e != nil || len(m.Servers) != 3 || m.Servers[0] != (mockHost{"env", 1}) || m.Servers[1] != (mockHost{"b", 9000}) || m.Servers[2].Host != "c" || m.Ports != [2]int{1, 3} {
+		t.Errorf("failed to patch array elements, %v %+v...", e, m)
+	}
+	if v, ok := c.Get("Servers.1.Host"); !ok || v != "b" {
+		t.Errorf("failed to get array element, %v...", v)
+	}
+	if _, ok := c.Get("Servers.3.Host"); ok {
+		t.Error("failed to report missing array element...")
+	}
+	if s := c.source("Servers.1.Port"); s != "option --set" {
+		t.Errorf("failed to record the source of an array element, %s...", s)
+	}
+
+	// test set patches a single element
+	if e := c.Set("Servers.0.Port", 8000); e != nil || m.Servers[0] != (mockHost{"env", 8000}) || m.Servers[1].Port != 9000 {
+		t.Errorf("failed to set array element, %v %+v...", e, m)
+	}
+	if v, _ := c.Get("/Servers/0/Port"); v != 8000 {
+		t.Errorf("failed to retain array element, %v...", v)
+	}
+
+	os.Clearenv()
+
+	// test overriding a scalar with a key beneath it
+	os.Args = []string{"app", "--set", "Ports=1", "--set", "Ports.0=2"}
+	m = &mockHosts{}
+	c = &Config{}
+	c.Target(m)
+	if e := c.Load("/tmp/app.json"); e != nil || m.Ports != [2]int{2, 2} {
+		t.Errorf("failed to replace scalar with nested key, %v %+v...", e, m)
+	}
+}
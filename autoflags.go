@@ -0,0 +1,51 @@
+package gonf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+var reserved = []string{"--help", "--version", "--config", "--set", "--check-config", "--dump-config"}
+
+func (c *Config) flagName(name string) string {
+	if c.keyCase != "" {
+		return "--" + strings.Join(c.words(strings.Replace(name, ".", "-", -1)), "-")
+	}
+	return "--" + strings.ToLower(strings.NewReplacer(".", "-", "_", "-").Replace(name))
+}
+
+// Register a long option for every field of the target that has not been
+// registered, using the description from its gonf tag when supplied.
+func (c *Config) flags(t reflect.Type, descriptions map[string]string) error {
+	c.mu.RLock()
+	auto := c.autoFlags
+	c.mu.RUnlock()
+	if !auto {
+		return nil
+	}
+	var errs []error
+	for _, n := range c.fields(t, "") {
+		c.mu.RLock()
+		o := c.flagName(n)
+		skip := c.setting(n) != nil || c.registered(o)
+		c.mu.RUnlock()
+		for _, r := range reserved {
+			skip = skip || o == r
+		}
+		if !skip {
+			errs = append(errs, c.Add(n, descriptions[n], "", o))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Generate a long command line option, and help entry, for every field of the
+// target that was not registered with Add or a gonf tag.  Options are named
+// by the json tag or property name in lower case with dots and underscores
+// replaced by hyphens (eg. `--server-name` for `Server.Name`).
+func (c *Config) AutoFlags() {
+	c.mu.Lock()
+	c.autoFlags = true
+	c.mu.Unlock()
+}
@@ -0,0 +1,52 @@
+package gonf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockFlags struct {
+	Port    int    `json:"port" gonf:"desc=listen port"`
+	Help    bool   `json:"help"`
+	Verbose bool   `json:"verbose"`
+	LogFile string `json:"log_file"`
+	Server  struct {
+		Name string
+	}
+}
+
+func TestAutoFlags(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(`{}`), nil }
+
+	// test disabled by default
+	os.Args = []string{"app", "--port", "80"}
+	mf := &mockFlags{}
+	c := &Config{}
+	c.Target(mf)
+	c.Add("verbose", "", "", "-v")
+	if e := c.Load("/tmp/app.json"); e != nil || mf.Port != 0 || c.setting("port") != nil {
+		t.Errorf("failed to ignore unregistered fields, %v...", e)
+	}
+
+	// test generated options, descriptions, and reserved or registered names
+	c.AutoFlags()
+	os.Args = []string{"app", "--port", "80", "--log-file", "out.log", "--server-name", "web", "-v"}
+	if e := c.Load("/tmp/app.json"); e != nil || mf.Port != 80 || mf.LogFile != "out.log" || mf.Server.Name != "web" || !mf.Verbose {
+		t.Errorf("failed to generate options, %v %+v...", e, mf)
+	}
+	if s := c.setting("port"); s == nil || strings.Join(s.Options, " ") != "--port" || s.Description != "listen port" {
+		t.Errorf("failed to register generated option, %+v...", s)
+	}
+	if c.setting("help") != nil || strings.Join(c.setting("verbose").Options, " ") != "-v" {
+		t.Error("failed to skip reserved or registered options...")
+	}
+
+	// test that a second load does not conflict
+	if e := c.Load("/tmp/app.json"); e != nil {
+		t.Errorf("failed to reload with generated options, %v...", e)
+	}
+}
@@ -0,0 +1,101 @@
+package gonf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A target that receives only the subtree of the configuration at a prefix.
+type binding struct {
+	prefix string
+	target interface{}
+}
+
+// Apply the subtree at each bound prefix to its target while the lock is
+// held, returning any errors.
+func (c *Config) bound(combo map[string]interface{}) []error {
+	var errs []error
+	for _, b := range c.binds {
+		v, ok := c.lookup(combo, b.prefix)
+		sub, is := v.(map[string]interface{})
+		if !ok || !is {
+			continue
+		}
+		data, _ := json.Marshal(sub)
+		m := map[string]interface{}{}
+		json.Unmarshal(data, &m)
+		unlock := c.writeLock(b.target)
+		for _, err := range c.apply(b.target, m) {
+			errs = append(errs, fmt.Errorf("%w (%s)", err, b.prefix))
+		}
+		unlock()
+	}
+	return errs
+}
+
+// Apply the subtree at each bound prefix to a copy of its target while the lock
+// is held, returning the copies in the order of the bindings, with nil for
+// those without a subtree, so that they are committed with the target.
+func (c *Config) stage(combo map[string]interface{}) ([]interface{}, []error) {
+	var errs []error
+	staged := make([]interface{}, len(c.binds))
+	for i, b := range c.binds {
+		v, ok := c.lookup(combo, b.prefix)
+		sub, is := v.(map[string]interface{})
+		if !ok || !is {
+			continue
+		}
+		data, _ := json.Marshal(sub)
+		m := map[string]interface{}{}
+		json.Unmarshal(data, &m)
+		cp := reflect.New(reflect.TypeOf(b.target).Elem())
+		unlock := c.readLock(b.target)
+		c.copyFields(cp.Elem(), reflect.ValueOf(b.target).Elem())
+		unlock()
+		for _, err := range c.apply(cp.Interface(), m) {
+			errs = append(errs, fmt.Errorf("%w (%s)", err, b.prefix))
+		}
+		staged[i] = cp.Interface()
+	}
+	return staged, errs
+}
+
+// Return the type bound to the prefix of a dot-notation key, and the remainder
+// of the key beneath it.
+func (c *Config) binding(name string) (reflect.Type, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, b := range c.binds {
+		if name == b.prefix {
+			return reflect.TypeOf(b.target), "", true
+		} else if strings.HasPrefix(name, b.prefix+".") {
+			return reflect.TypeOf(b.target), strings.TrimPrefix(name, b.prefix+"."), true
+		}
+	}
+	return nil, "", false
+}
+
+// Bind a pointer to a struct to a dot-notation prefix, so that it receives
+// only that subtree of the configuration on every Load, Reload, Apply, and
+// Set, in the same way as the target.  This allows independent modules to
+// each own their configuration without one struct for the application, and a
+// Target is not required when every key is bound.
+func (c *Config) Bind(prefix string, target interface{}) error {
+	if prefix == "" || strings.HasPrefix(prefix, ".") || strings.HasSuffix(prefix, ".") || strings.Contains(prefix, "..") {
+		return errBadNameSyntax
+	} else if t := reflect.TypeOf(target); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return errNotBindable
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, b := range c.binds {
+		if b.prefix == prefix {
+			c.binds[i].target = target
+			return nil
+		}
+	}
+	c.binds = append(c.binds, binding{prefix, target})
+	return nil
+}
@@ -0,0 +1,57 @@
+package gonf
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+type mockServer struct {
+	sync.Mutex
+	Port int    `json:"port"`
+	Host string `json:"host"`
+}
+
+type mockDatabase struct {
+	DSN string `json:"dsn"`
+}
+
+func TestBind(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"http": {"server": {"port": "8080", "host": "localhost"}}, "database": {"dsn": "file.db"}}`), nil
+	}
+	os.Args = []string{"app"}
+
+	c := &Config{}
+	if c.Bind("", &mockServer{}) == nil || c.Bind("http..server", &mockServer{}) == nil || c.Bind("database", mockDatabase{}) == nil {
+		t.Error("failed to reject bad bindings...")
+	}
+
+	// test subtrees applied without a target
+	ms, md := &mockServer{}, &mockDatabase{}
+	c.Bind("http.server", ms)
+	c.Bind("database", md)
+	c.Add("http.server.port", "", "APP_PORT")
+	os.Setenv("APP_PORT", "9090")
+	if e := c.Load("/tmp/app.json"); e != nil || ms.Port != 9090 || ms.Host != "localhost" || md.DSN != "file.db" {
+		t.Errorf("failed to apply bound subtrees, %v %+v %+v...", e, ms, md)
+	}
+
+	// test set and unknown keys beneath a binding
+	if e := c.Set("database.dsn", "other.db"); e != nil || md.DSN != "other.db" {
+		t.Errorf("failed to set bound key, %v...", e)
+	}
+	if c.known("database.missing") || !c.known("http.server.port") {
+		t.Error("failed to check keys against bound targets...")
+	}
+
+	// test alongside a target
+	mc := &mockConfig{}
+	c.Target(mc)
+	if e := c.to(map[string]interface{}{"database": map[string]interface{}{"dsn": nil}}); e != nil || md.DSN != "" {
+		t.Errorf("failed to apply bound subtree with a target, %v...", e)
+	}
+}
@@ -0,0 +1,29 @@
+package gonf
+
+import "fmt"
+
+// Register a document of built-in defaults in the format, either "json" or
+// "plist" (json when empty), typically read from an `embed.FS`.  It is merged
+// beneath the defaults of the target and every other source on each Load,
+// instead of populating the target by hand beforehand.
+func (c *Config) Defaults(data []byte, format string) error {
+	if format == "" {
+		format = "json"
+	} else if format != "json" && format != "plist" {
+		return errUnknownFormat
+	}
+	vars, err := c.decode("defaults."+format, data)
+	if err != nil {
+		return fmt.Errorf("%w defaults: %v", ErrParse, err)
+	}
+	c.mu.Lock()
+	c.builtin = vars
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Config) builtins() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clone(c.builtin)
+}
@@ -0,0 +1,48 @@
+package gonf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Args = []string{"app", "--number", "3"}
+	readfile = nil
+	stat = nil
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("OptionNumber", "", "", "--number")
+
+	// test invalid formats and data are rejected
+	if e := c.Defaults([]byte(`{}`), "yaml"); e != errUnknownFormat {
+		t.Errorf("failed to reject unknown format, %v...", e)
+	}
+	if e := c.Defaults([]byte(`not json`), ""); !errors.Is(e, ErrParse) {
+		t.Errorf("failed to report parse error, %v...", e)
+	}
+
+	// test built-in defaults are merged beneath every other source
+	if e := c.Defaults([]byte(`{"OptionNumber": 1, "EnvString": "builtin", "EnvBool": true, "ExplicitComposite": {"DepthByOption": 4}}`), "json"); e != nil {
+		t.Errorf("failed to register defaults, %v...", e)
+	}
+	if e := c.LoadReader(strings.NewReader(`{"EnvString": "reader", "ExplicitComposite": {"DepthByEnv": true}}`), ""); e != nil || mc.OptionNumber != 3 || mc.EnvString != "reader" || !mc.EnvBool || mc.ExplicitComposite.DepthByOption != 4 || !mc.ExplicitComposite.DepthByEnv || c.source("EnvBool") != "default" {
+		t.Errorf("failed to merge built-in defaults, %v %+v...", e, mc)
+	}
+
+	// test the registered document is not modified by loading
+	if c.builtin["EnvString"] != "builtin" {
+		t.Errorf("failed to preserve built-in defaults, %v...", c.builtin)
+	}
+
+	// test plist
+	plist := `<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict><key>EnvString</key><string>plist</string></dict></plist>`
+	if e := c.Defaults([]byte(plist), "plist"); e != nil || c.builtin["EnvString"] != "plist" {
+		t.Errorf("failed to register plist defaults, %v...", e)
+	}
+}
@@ -0,0 +1,134 @@
+package gonf
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Split a name into lower case words at underscores, hyphens, spaces, and
+// changes in case (eg. `maxConnections`, `max_connections`, and
+// `MAX_CONNECTIONS` all become `max connections`).
+func (c *Config) words(name string) []string {
+	var list []string
+	r := []rune(name)
+	start := 0
+	for i := 0; i <= len(r); i++ {
+		if i == len(r) || r[i] == '_' || r[i] == '-' || r[i] == ' ' {
+			if i > start {
+				list = append(list, strings.ToLower(string(r[start:i])))
+			}
+			start = i + 1
+		} else if i > start && unicode.IsUpper(r[i]) && (!unicode.IsUpper(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]))) {
+			list = append(list, strings.ToLower(string(r[start:i])))
+			start = i
+		}
+	}
+	return list
+}
+
+func (c *Config) fold(name string) string {
+	return strings.Join(c.words(name), "")
+}
+
+// Render each segment of a dot-notation name in the configured case.
+func (c *Config) render(name string) string {
+	segments := strings.Split(name, ".")
+	for i, s := range segments {
+		w := c.words(s)
+		switch c.keyCase {
+		case "snake":
+			segments[i] = strings.Join(w, "_")
+		case "kebab":
+			segments[i] = strings.Join(w, "-")
+		case "camel":
+			for j := 1; j < len(w); j++ {
+				w[j] = strings.ToUpper(w[j][:1]) + w[j][1:]
+			}
+			segments[i] = strings.Join(w, "")
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// Index the fields of a struct, including those of embedded structs, by the
+// folded form of their json tag or property name.
+func (c *Config) index(t reflect.Type, out map[string]reflect.StructField) map[string]reflect.StructField {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return out
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := strings.Split(f.Tag.Get("json"), ",")[0]
+		if n == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		} else if n == "" && f.Anonymous {
+			c.index(f.Type, out)
+			continue
+		} else if n == "" {
+			n = f.Name
+		}
+		f.Name = n
+		if _, ok := out[c.fold(n)]; !ok {
+			out[c.fold(n)] = f
+		}
+	}
+	return out
+}
+
+// Rename keys that match a field of the struct after folding, either to the
+// json name of the field when reading, or to the configured case when writing,
+// leaving keys of maps and unmatched keys alone.
+func (c *Config) rekey(t reflect.Type, in map[string]interface{}, write bool) map[string]interface{} {
+	fields := c.index(t, map[string]reflect.StructField{})
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		f, ok := fields[c.fold(k)]
+		if !ok {
+			out[k] = v
+			continue
+		}
+		if m, is := v.(map[string]interface{}); is {
+			v = c.rekey(f.Type, m, write)
+		}
+		if k = f.Name; write {
+			k = c.render(k)
+		}
+		if m1, is := out[k].(map[string]interface{}); is {
+			if m2, is := v.(map[string]interface{}); is {
+				v = c.merge(m1, m2)
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func (c *Config) normalize(vars map[string]interface{}) map[string]interface{} {
+	c.mu.RLock()
+	style, t := c.keyCase, reflect.TypeOf(c.target)
+	c.mu.RUnlock()
+	if style == "" || vars == nil {
+		return vars
+	}
+	return c.rekey(t, vars, false)
+}
+
+// Match keys from files, environment variables, command line options, and
+// struct tags to the target regardless of case and word separators, so that
+// `maxConnections`, `max_connections`, and `MAX_CONNECTIONS` all reach the same
+// field.  The style, one of "snake", "camel", "kebab", or "insensitive",
+// determines the keys written by Save and the names derived by EnvPrefix,
+// AutoEnv, and AutoFlags.  An empty style restores exact matching.
+func (c *Config) KeyCase(style string) error {
+	if style != "" && style != "snake" && style != "camel" && style != "kebab" && style != "insensitive" {
+		return errUnknownCase
+	}
+	c.mu.Lock()
+	c.keyCase = style
+	c.mu.Unlock()
+	return nil
+}
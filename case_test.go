@@ -0,0 +1,59 @@
+package gonf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockCase struct {
+	MaxConnections int `json:"maxConnections"`
+	HTTPServer     struct {
+		ReadTimeout int
+	}
+	Labels map[string]string `json:"labels"`
+}
+
+func TestKeyCase(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"max_connections": 5, "http-server": {"read_timeout": 3}, "labels": {"some_key": "a"}}`), nil
+	}
+	os.Args = []string{"app"}
+
+	c := &Config{}
+	if c.KeyCase("screaming") == nil {
+		t.Error("failed to reject unknown case...")
+	}
+	if w := strings.Join(c.words("HTTPServer.max_connections-maxConnections"), " "); w != "http server.max connections max connections" {
+		t.Errorf("failed to split words, %s...", w)
+	}
+
+	// test disabled by default
+	mc := &mockCase{}
+	c.Target(mc)
+	c.Strict(true)
+	if e := c.Load("/tmp/app.json"); e == nil || mc.MaxConnections != 0 {
+		t.Error("failed to reject mismatched keys by default...")
+	}
+
+	// test files, environment variables, and options
+	c.KeyCase("snake")
+	c.AutoEnv()
+	c.AutoFlags()
+	os.Setenv("HTTP_SERVER_READ_TIMEOUT", "7")
+	os.Args = []string{"app", "--max-connections", "9"}
+	if e := c.Load("/tmp/app.json"); e != nil || mc.MaxConnections != 9 || mc.HTTPServer.ReadTimeout != 7 || mc.Labels["some_key"] != "a" {
+		t.Errorf("failed to normalize keys, %v %+v...", e, mc)
+	}
+
+	// test keys written by save
+	for style, expect := range map[string]string{"snake": `"max_connections"`, "camel": `"maxConnections"`, "kebab": `"http-server"`, "insensitive": `"HTTPServer"`} {
+		c.KeyCase(style)
+		if data, e := c.encode("app.json"); e != nil || !strings.Contains(string(data), expect) || !strings.Contains(string(data), `"some_key"`) {
+			t.Errorf("failed to write %s keys, %v %s...", style, e, data)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gonf
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+func (c *Config) report(err error) {
+	c.printf("configuration file: %s\n", c.ConfigFile())
+	for _, e := range c.entries() {
+		v, _ := json.Marshal(e.Value)
+		c.printf("\t%s = %s\t# %s\n", e.Key, v, e.Source)
+	}
+	if err == nil {
+		c.printf("configuration is valid\n")
+		return
+	}
+	for _, e := range strings.Split(err.Error(), "\n") {
+		c.printf("error: %s\n", e)
+	}
+	c.printf("configuration is invalid\n")
+}
+
+// Run the complete load, cast, and validation pipeline without saving any
+// defaults, then print the effective configuration with sensitive values
+// masked, along with any problems, and return the errors.  The built-in
+// `--check-config` command line option does the same during Load, and then
+// terminates the application, like `nginx -t`.
+func (c *Config) Check(filenames ...string) error {
+	c.mu.Lock()
+	c.dryRun = true
+	c.mu.Unlock()
+	err := c.Load(filenames...)
+	c.mu.Lock()
+	c.dryRun = false
+	c.mu.Unlock()
+	c.report(err)
+	return err
+}
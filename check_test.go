@@ -0,0 +1,50 @@
+package gonf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	var exitCode int = -1
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+	exit = func(i int) { exitCode = i }
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return nil, os.ErrNotExist }
+	createTemp = nil
+	defer func() { createTemp = ioutil.TempFile }()
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	c.Add("OptionString", "", "", "--string")
+	c.Add("EnvString", "", "", "--secret")
+	c.Choices("OptionString", "a", "b")
+
+	// test check without saving defaults
+	os.Args = []string{"app", "--string", "a", "--secret", "hidden"}
+	if e := c.Check("app.json"); e != nil || !strings.Contains(fmtPrintfData, `OptionString = "a"`) || !strings.Contains(fmtPrintfData, `EnvString = "`+redacted+`"`) || !strings.Contains(fmtPrintfData, "configuration is valid") || exitCode != -1 {
+		t.Errorf("failed to check configuration, %v %s...", e, fmtPrintfData)
+	}
+
+	// test built-in option terminates with problems
+	fmtPrintfData = ""
+	os.Args = []string{"app", "--check-config", "--string", "c"}
+	if c.Load("app.json"); exitCode != 1 || !strings.Contains(fmtPrintfData, "error: ") || !strings.Contains(fmtPrintfData, "configuration is invalid") {
+		t.Errorf("failed to check configuration from the command line, %d %s...", exitCode, fmtPrintfData)
+	}
+	os.Args = []string{"app", "--check-config"}
+	if c.Load("app.json"); exitCode != 0 {
+		t.Errorf("failed to exit successfully when checking valid configuration, %d...", exitCode)
+	}
+}
@@ -0,0 +1,41 @@
+package gonf
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Compare data with the SHA-256 checksum in the sidecar file beside it, in the
+// format written by sha256sum, while the lock is held.
+func (c *Config) verify(name string, data []byte) error {
+	if !c.checksums {
+		return nil
+	}
+	sidecar, err := c.read(name + ".sha256")
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrChecksum, name, err)
+	}
+	fields := strings.Fields(string(sidecar))
+	want, err := hex.DecodeString(strings.Join(fields[:min(len(fields), 1)], ""))
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("%w %s: malformed %s.sha256", ErrChecksum, name, name)
+	}
+	if sum := sha256.Sum256(data); subtle.ConstantTimeCompare(sum[:], want) != 1 {
+		return fmt.Errorf("%w %s: expected %x, found %x", ErrChecksum, name, want, sum)
+	}
+	return nil
+}
+
+// Require every configuration file, system file, and drop-in file to match
+// the SHA-256 checksum in a sidecar file with a .sha256 suffix (eg.
+// `app.json.sha256`, as written by `sha256sum app.json`), so that corrupted or
+// truncated files are refused with ErrChecksum, and a reload keeps the
+// configuration in effect.  Save rewrites the sidecar of the file it writes.
+func (c *Config) Checksums(enabled bool) {
+	c.mu.Lock()
+	c.checksums = enabled
+	c.mu.Unlock()
+}
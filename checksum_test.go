@@ -0,0 +1,100 @@
+package gonf
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecksums(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	data := `{"OptionNumber": 1}`
+	sum := fmt.Sprintf("%x  app.json\n", sha256.Sum256([]byte(data)))
+	modTime := time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(name string) ([]byte, error) {
+		if strings.HasSuffix(name, ".sha256") {
+			return []byte(sum), nil
+		}
+		return []byte(data), nil
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Checksums(true)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionNumber != 1 {
+		t.Errorf("failed to load file matching checksum, %v %v...", e, mc.OptionNumber)
+	}
+
+	// test truncated files are refused and the configuration is kept
+	data, modTime = `{"OptionNumber": 2`, modTime.Add(time.Second)
+	if e := c.Reload(); !errors.Is(e, ErrChecksum) || mc.OptionNumber != 1 {
+		t.Errorf("failed to refuse truncated file, %v %v...", e, mc.OptionNumber)
+	}
+
+	// test the file is accepted once the checksum matches
+	data = `{"OptionNumber": 2}`
+	sum, modTime = fmt.Sprintf("%x\n", sha256.Sum256([]byte(data))), modTime.Add(time.Second)
+	if e := c.Reload(); e != nil || mc.OptionNumber != 2 {
+		t.Errorf("failed to reload file matching checksum, %v %v...", e, mc.OptionNumber)
+	}
+
+	// test malformed checksums are refused
+	sum, modTime = "not a checksum", modTime.Add(time.Second)
+	if e := c.Reload(); !errors.Is(e, ErrChecksum) {
+		t.Errorf("failed to refuse malformed checksum, %v...", e)
+	}
+
+	// test system files are verified
+	defer func(s []string) { system = s }(system)
+	system = []string{"/etc/xdg"}
+	c.SetPaths("/home/user")
+	readfile = func(name string) ([]byte, error) {
+		if strings.HasPrefix(name, "/etc/xdg") && strings.HasSuffix(name, ".sha256") {
+			return []byte(fmt.Sprintf("%x\n", sha256.Sum256(nil))), nil
+		} else if strings.HasPrefix(name, "/etc/xdg") {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	if e := c.Load("app.json"); !errors.Is(e, ErrChecksum) {
+		t.Errorf("failed to refuse system file, %v...", e)
+	}
+}
+
+func TestChecksumsSave(t *testing.T) {
+	d, e := ioutil.TempDir(os.TempDir(), "gonf")
+	if e != nil {
+		t.Fatal("failed to acquire temporary directory...")
+	}
+	defer os.RemoveAll(d)
+	stat, readfile, createTemp, rename = os.Stat, ioutil.ReadFile, ioutil.TempFile, os.Rename
+	os.Args = []string{}
+	os.Clearenv()
+	cf := filepath.Join(d, "app.json")
+
+	mc := &mockConfig{OptionNumber: 3}
+	c := &Config{configFile: cf}
+	c.Target(mc)
+	c.Checksums(true)
+	if e := c.Save(); e != nil {
+		t.Fatalf("failed to save, %v...", e)
+	}
+	data, _ := ioutil.ReadFile(cf)
+	sum, _ := ioutil.ReadFile(cf + ".sha256")
+	if string(sum) != fmt.Sprintf("%x  app.json\n", sha256.Sum256(data)) {
+		t.Errorf("failed to rewrite the checksum, %s...", sum)
+	}
+	if e := c.Load(cf); e != nil || mc.OptionNumber != 3 {
+		t.Errorf("failed to load saved file, %v %v...", e, mc.OptionNumber)
+	}
+}
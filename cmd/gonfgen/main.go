@@ -0,0 +1,294 @@
+// Command gonfgen generates an ApplyConfig function for configuration
+// targets, so that gonf applies configuration to them without reflection or
+// json round-trips.  Add a directive to the package declaring the target:
+//
+//	//go:generate gonfgen -type Config
+//
+// Fields of types it does not handle directly, such as maps or types from
+// other packages, are decoded through json individually.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var bits = map[string]string{
+	"int": "0", "int8": "8", "int16": "16", "int32": "32", "int64": "64",
+	"uint": "0", "uint8": "8", "uint16": "16", "uint32": "32", "uint64": "64",
+	"float32": "32", "float64": "64", "byte": "8", "rune": "32",
+}
+
+// A field reachable from a target, keyed by the lower case json name that
+// encoding/json would match it with.
+type field struct {
+	key  string
+	path string
+	typ  ast.Expr
+}
+
+type generator struct {
+	fset    *token.FileSet
+	pkg     string
+	structs map[string]*ast.StructType
+	queue   []string
+	done    map[string]bool
+	buf     bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) source(e ast.Expr) string {
+	b := &bytes.Buffer{}
+	printer.Fprint(b, g.fset, e)
+	return b.String()
+}
+
+func (g *generator) parse(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, "_gonf.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, name, nil, 0)
+		if err != nil {
+			return err
+		}
+		g.pkg = f.Name.Name
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				if ts := s.(*ast.TypeSpec); ts.TypeParams == nil {
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						g.structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Collect the fields of a struct the way encoding/json would, promoting the
+// fields of embedded structs declared in the package, and returning the paths
+// of any other embedded structs.
+func (g *generator) fields(prefix string, st *ast.StructType, seen map[string]bool, out []field) ([]field, []string) {
+	var external []string
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			t, _ := strconv.Unquote(f.Tag.Value)
+			tag = strings.Split(reflect.StructTag(t).Get("json"), ",")[0]
+		}
+		if tag == "-" {
+			continue
+		}
+		if len(f.Names) == 0 {
+			name := g.source(f.Type)
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+			if st, ok := g.structs[name]; ok && tag == "" && !strings.HasPrefix(g.source(f.Type), "*") {
+				var ext []string
+				out, ext = g.fields(prefix+name+".", st, seen, out)
+				external = append(external, ext...)
+			} else if src := g.source(f.Type); tag == "" && ast.IsExported(name) && !strings.HasPrefix(src, "*") && strings.Contains(src, ".") {
+				if !strings.HasPrefix(src, "sync.") {
+					external = append(external, prefix+name)
+				}
+			} else if ast.IsExported(name) || tag != "" {
+				out = g.add(out, seen, tag, name, prefix+name, f.Type)
+			}
+			continue
+		}
+		for _, n := range f.Names {
+			if ast.IsExported(n.Name) {
+				out = g.add(out, seen, tag, n.Name, prefix+n.Name, f.Type)
+			}
+		}
+	}
+	return out, external
+}
+
+func (g *generator) add(out []field, seen map[string]bool, tag, name, path string, typ ast.Expr) []field {
+	if tag == "" {
+		tag = name
+	}
+	if key := strings.ToLower(tag); !seen[key] {
+		seen[key] = true
+		out = append(out, field{key, path, typ})
+	}
+	return out
+}
+
+// Emit the conversion of a value to a primitive, or return false.
+func (g *generator) primitive(dst, typ, v, key string) bool {
+	var call string
+	switch {
+	case typ == "bool":
+		call = "gonf.ToBool(" + v + ")"
+	case typ == "string":
+		call = "gonf.ToString(" + v + ")"
+	case strings.HasPrefix(typ, "int") || typ == "rune":
+		call = "gonf.ToInt(" + v + ", " + bits[typ] + ")"
+	case strings.HasPrefix(typ, "uint") || typ == "byte":
+		call = "gonf.ToUint(" + v + ", " + bits[typ] + ")"
+	case strings.HasPrefix(typ, "float"):
+		call = "gonf.ToFloat(" + v + ", " + bits[typ] + ")"
+	}
+	if call == "" || (bits[typ] == "" && typ != "bool" && typ != "string") {
+		return false
+	}
+	g.printf("if r, err := %s; err != nil {\nerrs = append(errs, fmt.Errorf(\"%s: %%w\", err))\n} else {\n%s = %s(r)\n}\n", call, key, dst, typ)
+	return true
+}
+
+// Emit the assignment of a value to a destination of the type.
+func (g *generator) value(dst string, typ ast.Expr, v, key string, depth int) {
+	g.printf("if %s == nil {\ngonf.Zero(&%s)\n} else ", v, dst)
+	name := g.source(typ)
+	if g.primitive(dst, name, v, key) {
+		return
+	}
+	sub := fmt.Sprintf("m%d", depth)
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if _, ok := g.structs[t.Name]; ok {
+			g.enqueue(t.Name)
+			g.printf("if %s, ok := %s.(map[string]interface{}); ok {\nif err := %s.ApplyConfig(%s); err != nil {\nerrs = append(errs, err)\n}\n} else {\nerrs = append(errs, fmt.Errorf(\"%s: %%w %%v to object\", gonf.ErrCast, %s))\n}\n", sub, v, dst, sub, key, v)
+			return
+		}
+	case *ast.StarExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			if _, ok := g.structs[id.Name]; ok {
+				g.enqueue(id.Name)
+				g.printf("if %s, ok := %s.(map[string]interface{}); ok {\nif %s == nil {\n%s = new(%s)\n}\nif err := %s.ApplyConfig(%s); err != nil {\nerrs = append(errs, err)\n}\n} else {\nerrs = append(errs, fmt.Errorf(\"%s: %%w %%v to object\", gonf.ErrCast, %s))\n}\n", sub, v, dst, dst, id.Name, dst, sub, key, v)
+				return
+			}
+		}
+	case *ast.StructType:
+		g.printf("if %s, ok := %s.(map[string]interface{}); ok {\n", sub, v)
+		g.object(dst+".", t, sub, key+".", depth+1)
+		g.printf("} else {\nerrs = append(errs, fmt.Errorf(\"%s: %%w %%v to object\", gonf.ErrCast, %s))\n}\n", key, v)
+		return
+	case *ast.ArrayType:
+		if e := g.source(t.Elt); t.Len == nil && e != "byte" && e != "uint8" && (bits[e] != "" || e == "bool" || e == "string") {
+			l, i, r := fmt.Sprintf("l%d", depth), fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+			g.printf("if %s, ok := %s.([]interface{}); ok {\n%s = make(%s, len(%s))\nfor %s, %s := range %s {\n", l, v, dst, name, l, i, r, l)
+			g.primitive(dst+"["+i+"]", e, r, key)
+			g.printf("}\n} else ")
+		}
+	}
+	g.printf("if err := gonf.Decode(%s, &%s); err != nil {\nerrs = append(errs, fmt.Errorf(\"%s: %%w\", err))\n}\n", v, dst, key)
+}
+
+// Emit a loop applying every key of a map to the fields of a struct.
+func (g *generator) object(prefix string, st *ast.StructType, m, key string, depth int) {
+	fields, external := g.fields(prefix, st, map[string]bool{}, nil)
+	k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+	g.printf("for %s, %s := range %s {\nswitch strings.ToLower(%s) {\n", k, v, m, k)
+	for _, f := range fields {
+		g.printf("case %q:\n", f.key)
+		g.value(f.path, f.typ, v, key+f.key, depth)
+	}
+	if len(external) > 0 {
+		g.printf("default:\n")
+		for _, e := range external {
+			g.printf("if err := gonf.Decode(map[string]interface{}{%s: %s}, &%s); err != nil {\nerrs = append(errs, err)\n}\n", k, v, e)
+		}
+	} else if len(fields) == 0 {
+		g.printf("default:\n_ = %s\n", v)
+	}
+	g.printf("}\n}\n")
+}
+
+func (g *generator) enqueue(name string) {
+	if !g.done[name] {
+		g.done[name] = true
+		g.queue = append(g.queue, name)
+	}
+}
+
+// Generate the formatted source of ApplyConfig for each named struct, and any
+// named structs of the package that they contain.
+func (g *generator) generate(names ...string) ([]byte, error) {
+	for _, n := range names {
+		if _, ok := g.structs[n]; !ok {
+			return nil, fmt.Errorf("struct type %s not found", n)
+		}
+		g.enqueue(n)
+	}
+	var body bytes.Buffer
+	for len(g.queue) > 0 {
+		n := g.queue[0]
+		g.queue = g.queue[1:]
+		g.buf.Reset()
+		g.printf("\n// ApplyConfig applies a merged configuration to %s without reflection.\n", n)
+		g.printf("func (t *%s) ApplyConfig(m map[string]interface{}) error {\nvar errs []error\n", n)
+		g.object("t.", g.structs[n], "m", "", 0)
+		g.printf("return errors.Join(errs...)\n}\n")
+		body.Write(g.buf.Bytes())
+	}
+	g.buf.Reset()
+	g.printf("// Code generated by gonfgen; DO NOT EDIT.\n\npackage %s\n\n", g.pkg)
+	g.printf("import (\n\"errors\"\n")
+	if bytes.Contains(body.Bytes(), []byte("fmt.")) {
+		g.printf("\"fmt\"\n")
+	}
+	g.printf("\"strings\"\n")
+	if bytes.Contains(body.Bytes(), []byte("gonf.")) {
+		g.printf("\n\"github.com/cdelorme/gonf\"\n")
+	}
+	g.printf(")\n")
+	g.buf.Write(body.Bytes())
+	return format.Source(g.buf.Bytes())
+}
+
+func main() {
+	types := flag.String("type", "", "comma separated struct type names")
+	output := flag.String("output", "", "output file name (default <type>_gonf.go)")
+	flag.Parse()
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	names := strings.Split(*types, ",")
+	sort.Strings(names)
+	g := &generator{fset: token.NewFileSet(), structs: map[string]*ast.StructType{}, done: map[string]bool{}}
+	if err := g.parse("."); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	src, err := g.generate(names...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = strings.ToLower(names[0]) + "_gonf.go"
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	d := t.TempDir()
+	src, err := os.ReadFile(filepath.Join("..", "..", "apply_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(d, "applied.go"), src, 0644)
+	os.WriteFile(filepath.Join(d, "empty.go"), []byte("package gonf_test\n\ntype Empty struct{}\n"), 0644)
+
+	// test the generated source matches the fixture used by the gonf tests
+	g := &generator{fset: token.NewFileSet(), structs: map[string]*ast.StructType{}, done: map[string]bool{}}
+	if err := g.parse(d); err != nil {
+		t.Fatal(err)
+	}
+	out, err := g.generate("Applied")
+	expect, _ := os.ReadFile(filepath.Join("..", "..", "applied_gonf_test.go"))
+	if err != nil || string(out) != string(expect) {
+		t.Errorf("failed to generate the expected source, %v\n%s...", err, out)
+	}
+
+	// test structs without fields and missing types
+	g = &generator{fset: g.fset, pkg: g.pkg, structs: g.structs, done: map[string]bool{}}
+	if out, err := g.generate("Empty"); err != nil || strings.Contains(string(out), "fmt") {
+		t.Errorf("failed to generate for an empty struct, %v\n%s...", err, out)
+	}
+	if _, err := g.generate("Missing"); err == nil {
+		t.Error("failed to reject a missing type...")
+	}
+}
@@ -0,0 +1,61 @@
+package gonf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+const (
+	bold   = "1"
+	green  = "32"
+	yellow = "33"
+)
+
+var terminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Wraps text in an ANSI color code.
+type painter func(code, s string) string
+
+func plain(_, s string) string {
+	return s
+}
+
+func ansi(code, s string) string {
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// Return the painter for help while the lock is held, which colors only when
+// enabled, writing to a terminal, and NO_COLOR is not set.
+func (c *Config) painter() painter {
+	if !c.color || os.Getenv("NO_COLOR") != "" || !terminal(c.writer()) {
+		return plain
+	}
+	return ansi
+}
+
+// Pad text to a width with spaces before painting it, so that colors do not
+// break the alignment of help.
+func (c *Config) pad(paint painter, code, s string, width int) string {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return paint(code, s) + fmt.Sprintf("%*s", n, "")
+	}
+	return paint(code, s)
+}
+
+// Enable ANSI colors for flag names, values, and section headers in help,
+// which are automatically disabled when the output is not a terminal or the
+// NO_COLOR environment variable is set.
+func (c *Config) Color(enabled bool) {
+	c.mu.Lock()
+	c.color = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,52 @@
+package gonf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	defer func(f func(io.Writer) bool) { terminal = f }(terminal)
+	terminal = func(io.Writer) bool { return true }
+	os.Setenv("APP_LEVEL", "debug")
+
+	out := &bytes.Buffer{}
+	c := &Config{}
+	c.Output(out)
+	c.Description("testing colors")
+	c.Add("level", "logging level", "APP_LEVEL", "--level")
+	c.Choices("level", "debug", "info")
+	c.HelpValues(true)
+
+	// test colors are disabled by default
+	c.Help()
+	if strings.Contains(out.String(), "\x1b[") || !strings.Contains(out.String(), "\tAPP_LEVEL=debug"+strings.Repeat(" ", 15)+"\n") {
+		t.Errorf("failed to print help without colors, %q...", out.String())
+	}
+
+	// test headers, flags, and values are colored
+	out.Reset()
+	c.Color(true)
+	c.Help()
+	for _, s := range []string{"\x1b[1mFlags:\x1b[0m", "\x1b[32m--level (APP_LEVEL)", "\x1b[33mdebug, info\x1b[0m", "\x1b[32mAPP_LEVEL\x1b[0m=\x1b[33mdebug\x1b[0m" + strings.Repeat(" ", 15)} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("failed to color %q in help, %q...", s, out.String())
+		}
+	}
+
+	// test NO_COLOR and non-terminals disable colors
+	out.Reset()
+	os.Setenv("NO_COLOR", "1")
+	c.Help()
+	os.Unsetenv("NO_COLOR")
+	terminal = func(io.Writer) bool { return false }
+	c.Help()
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Errorf("failed to disable colors, %q...", out.String())
+	}
+}
@@ -0,0 +1,164 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var errUnsupportedShell = errors.New("unsupported shell for completion...")
+
+type completion struct {
+	Short       []string
+	Long        []string
+	Description string
+	Value       bool
+	Choices     []string
+}
+
+func (c *Config) completions() []completion {
+	c.mu.RLock()
+	settings := append([]setting{}, c.settings...)
+	version := c.version
+	c.mu.RUnlock()
+	list := []completion{{Short: []string{"h"}, Long: []string{"help"}, Description: "display help information"}}
+	if version != "" {
+		list = append(list, completion{Short: []string{"V"}, Long: []string{"version"}, Description: "display version information"})
+	}
+	for _, s := range settings {
+		if s.Hidden || len(s.Options) == 0 {
+			continue
+		}
+		o := completion{Description: s.Description, Choices: s.Choices}
+		if t, ok := c.field(s.Name); !ok || t.Kind() != reflect.Bool {
+			o.Value = true
+		}
+		for _, f := range s.Options {
+			f = strings.TrimSuffix(f, ":")
+			if strings.HasPrefix(f, "--") {
+				o.Long = append(o.Long, strings.TrimPrefix(f, "--"))
+			} else {
+				o.Short = append(o.Short, strings.TrimPrefix(f, "-"))
+			}
+		}
+		list = append(list, o)
+	}
+	return list
+}
+
+func (c *Config) quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func (c *Config) bash() string {
+	var words []string
+	for _, o := range c.completions() {
+		for _, s := range o.Short {
+			words = append(words, "-"+s)
+		}
+		for _, l := range o.Long {
+			words = append(words, "--"+l)
+		}
+	}
+	name := c.application()
+	fn := "_" + regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(name, "_")
+	return fmt.Sprintf("%s() {\n\tCOMPREPLY=($(compgen -W %s -- \"${COMP_WORDS[COMP_CWORD]}\"))\n}\ncomplete -F %s %s\n", fn, c.quote(strings.Join(words, " ")), fn, name)
+}
+
+func (c *Config) zsh() string {
+	r := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+	out := "#compdef " + c.application() + "\n\n_arguments \\\n"
+	for _, o := range c.completions() {
+		var flags []string
+		for _, s := range o.Short {
+			flags = append(flags, "-"+s)
+		}
+		for _, l := range o.Long {
+			flags = append(flags, "--"+l)
+		}
+		arg := ""
+		if len(o.Choices) > 0 {
+			arg = ":value:(" + r.Replace(strings.Join(o.Choices, " ")) + ")"
+		} else if o.Value {
+			arg = ":value: "
+		}
+		for _, f := range flags {
+			out += "\t'" + f + "[" + r.Replace(o.Description) + "]" + arg + "' \\\n"
+		}
+	}
+	return strings.TrimSuffix(out, " \\\n") + "\n"
+}
+
+func (c *Config) fish() string {
+	var out string
+	name := c.application()
+	for _, o := range c.completions() {
+		line := "complete -c " + name
+		for _, s := range o.Short {
+			if len([]rune(s)) == 1 {
+				line += " -s " + s
+			} else {
+				line += " -o " + s
+			}
+		}
+		for _, l := range o.Long {
+			line += " -l " + l
+		}
+		if o.Value {
+			line += " -r"
+		}
+		if len(o.Choices) > 0 {
+			line += " -f -a " + c.quote(strings.Join(o.Choices, " "))
+		}
+		if o.Description != "" {
+			line += " -d " + c.quote(o.Description)
+		}
+		out += line + "\n"
+	}
+	return out
+}
+
+func (c *Config) powershell() string {
+	q := func(s string) string { return "'" + strings.Replace(s, "'", "''", -1) + "'" }
+	out := "Register-ArgumentCompleter -Native -CommandName " + q(c.application()) + " -ScriptBlock {\n"
+	out += "\tparam($wordToComplete, $commandAst, $cursorPosition)\n\t@(\n"
+	for _, o := range c.completions() {
+		var flags []string
+		for _, s := range o.Short {
+			flags = append(flags, "-"+s)
+		}
+		for _, l := range o.Long {
+			flags = append(flags, "--"+l)
+		}
+		for _, f := range flags {
+			d := o.Description
+			if d == "" {
+				d = f
+			}
+			out += "\t\t[pscustomobject]@{ Flag = " + q(f) + "; Description = " + q(d) + " }\n"
+		}
+	}
+	out += "\t) | Where-Object { $_.Flag -clike \"$wordToComplete*\" } | ForEach-Object {\n"
+	out += "\t\t[System.Management.Automation.CompletionResult]::new($_.Flag, $_.Flag, 'ParameterName', $_.Description)\n"
+	return out + "\t}\n}\n"
+}
+
+// Generate a shell completion script for the registered command line options,
+// supporting bash, zsh, fish, and powershell.  All but bash include the
+// description of each setting, and any choices are offered as values by zsh
+// and fish.  Hidden settings are omitted.
+func (c *Config) Completion(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return c.bash(), nil
+	case "zsh":
+		return c.zsh(), nil
+	case "fish":
+		return c.fish(), nil
+	case "powershell":
+		return c.powershell(), nil
+	}
+	return "", errUnsupportedShell
+}
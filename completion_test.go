@@ -0,0 +1,43 @@
+package gonf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Add("OptionString", "the string's value", "", "-s:", "--string")
+	c.Add("OptionBool", "a [boolean]", "", "--bool")
+	c.Add("EnvString", "environment only", "ENV_STRING")
+	c.Add("GetoptSkip", "internal", "", "--internal")
+	c.Choices("OptionString", "one", "two")
+	c.Hidden("GetoptSkip")
+
+	if _, e := c.Completion("tcsh"); e == nil {
+		t.Error("failed to reject unsupported shell...")
+	}
+
+	if o, e := c.Completion("bash"); e != nil || !strings.Contains(o, "-h --help -s --string --bool") || strings.Contains(o, "--internal") {
+		t.Errorf("failed to generate bash completion, %s...", o)
+	}
+
+	if o, e := c.Completion("zsh"); e != nil || !strings.HasPrefix(o, "#compdef") ||
+		!strings.Contains(o, `'--string[the string'\''s value]:value:(one two)'`) ||
+		!strings.Contains(o, `'--bool[a \[boolean\]]'`) || strings.Contains(o, "--internal") || strings.HasSuffix(o, "\\\n") {
+		t.Errorf("failed to generate zsh completion, %s...", o)
+	}
+
+	if o, e := c.Completion("fish"); e != nil ||
+		!strings.Contains(o, `complete -c `+appName+` -s s -l string -r -f -a 'one two' -d 'the string'\''s value'`) ||
+		!strings.Contains(o, "-l bool -d 'a [boolean]'") || strings.Contains(o, "internal") {
+		t.Errorf("failed to generate fish completion, %s...", o)
+	}
+
+	if o, e := c.Completion("powershell"); e != nil || !strings.HasPrefix(o, "Register-ArgumentCompleter -Native -CommandName '"+appName+"'") ||
+		!strings.Contains(o, "[pscustomobject]@{ Flag = '--string'; Description = 'the string''s value' }") ||
+		!strings.Contains(o, "[pscustomobject]@{ Flag = '-h'; Description = 'display help information' }") || strings.Contains(o, "internal") {
+		t.Errorf("failed to generate powershell completion, %s...", o)
+	}
+}
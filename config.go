@@ -1,18 +1,25 @@
 package gonf
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
-	"regexp"
-	"strconv"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -23,15 +30,34 @@ var (
 	errNoEnvOptions   = errors.New("environment variable must not be empty or at least one command line option is expected...")
 	errBadNameSyntax  = errors.New("bad syntax for child properties...")
 	errConflictingAdd = errors.New("duplicate option detected...")
+	errNotRegistered  = errors.New("name has not been registered...")
+	errInvalidChoice  = errors.New("value is not one of the allowed choices...")
+	errUnknownFormat  = errors.New("unsupported configuration file format...")
+	errNotSwappable   = errors.New("target must be a pointer to a struct to swap...")
+	errBadTag         = errors.New("gonf tag name must match the json name of the field...")
+	errUnknownCase    = errors.New("unsupported key case...")
+	errNotBindable    = errors.New("bound target must be a pointer to a struct...")
+	errReadOnly       = errors.New("the file system is read-only...")
 
-	fmtPrintf = fmt.Printf
-	readfile  = ioutil.ReadFile
-	mkdirall  = os.MkdirAll
-	create    = os.Create
-	stat      = os.Stat
-	exit      = os.Exit
+	fmtPrintf  = fmt.Printf
+	readfile   = ioutil.ReadFile
+	mkdirall   = os.MkdirAll
+	createTemp = ioutil.TempFile
+	rename     = os.Rename
+	remove     = os.Remove
+	chown      = os.Chown
+	stat       = os.Stat
+	exit       = os.Exit
+
+	readBuildInfo = debug.ReadBuildInfo
+
+	stdout io.Writer = os.Stdout
 )
 
+// The deepest nesting of properties that reflection will walk, which guards
+// against recursive types.
+const maxDepth = 32
+
 type locker interface {
 	Lock()
 	Unlock()
@@ -44,10 +70,103 @@ type Config struct {
 	mu             sync.RWMutex
 	target         interface{}
 	description    string
+	name           atomic.Value
+	root           atomic.Value
+	version        string
+	authors        []string
+	homepage       string
+	license        string
+	envPrefix      string
+	format         string
+	comments       map[string][]string
+	dirMode        os.FileMode
+	fileMode       os.FileMode
+	owner          bool
+	uid, gid       int
+	redact         bool
+	commands       bool
+	resolving      bool
+	templates      bool
+	derived        []derived
+	featureFlags   bool
+	features       map[string]bool
+	onFeature      []feature
+	values         []func()
+	depth          int
+	historyDir     string
+	history        []Revision
+	baseline       map[string]interface{}
+	webhook        string
+	retries        int
+	deliveries     chan struct{}
+	bytesRead      atomic.Int64
+	stats          Stats
+	debug          bool
+	traces         []string
+	checksums      bool
+	keyMapper      func(string) string
+	hooks          []DecodeHook
+	variants       []variant
+	discriminator  string
+	started        bool
+	added          []setting
+	debounce       time.Duration
+	queued         *queued
+	reloaded       time.Time
+	check          bool
+	dryRun         bool
+	dump           bool
+	handling       ErrorHandling
+	color          bool
+	helpValues     bool
+	halted         error
+	output         atomic.Value
+	sources        map[string]map[string]string
+	status         status
+	metrics        Metrics
+	slog           *slog.Logger
+	pending        []string
+	merged         map[string]interface{}
+	swap           bool
+	current        atomic.Value
+	locker         sync.Locker
+	required       map[string]struct{}
+	references     map[string]reference
+	key            []byte
+	encrypted      bool
+	autoEnv        bool
+	autoFlags      bool
+	keyCase        string
+	clearEmpty     bool
+	binds          []binding
+	mounts         []mount
+	strict         bool
+	aliases        []alias
+	schema         *schema
+	inPlace        bool
+	builtin        map[string]interface{}
+	filesystem     atomic.Value
+	lines          map[string]int
 	configFile     string
+	override       string
 	configModified time.Time
-	examples       []string
+	dropins        string
+	paths          []string
+	patterns       []string
+	portable       bool
+	system         map[string]interface{}
+	systemFile     string
+	systemSig      string
+	examples       []example
 	settings       []setting
+	groups         []string
+	args           []string
+	sensitive      map[string]struct{}
+	onReload       []func(ChangeSet)
+	subscribers    []chan ChangeSet
+	watchers       []watcher
+	transactional  bool
+	frozen         bool
 }
 
 func (c *Config) isNumeric(t reflect.Kind) bool {
@@ -58,69 +177,109 @@ func (c *Config) isNumeric(t reflect.Kind) bool {
 	return false
 }
 
-func (c *Config) convert(d reflect.Value, v interface{}) interface{} {
-	t := d.Kind()
-	in := reflect.TypeOf(v).Kind()
-	switch {
-	case in == reflect.String && t == reflect.Bool:
-		if r, err := strconv.ParseBool(v.(string)); err == nil {
-			return r
+func (c *Config) structField(t reflect.Type, k string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if n := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; n != "-" && n == k {
+			return t.Field(i), true
 		}
-	case in == reflect.String && c.isNumeric(t):
-		if r, err := strconv.ParseFloat(v.(string), 64); err == nil {
-			return r
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if n := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; n == "" && t.Field(i).Name == k {
+			return t.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if n := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; n != "" || !t.Field(i).Anonymous || t.Field(i).Type.Kind() != reflect.Struct {
+			continue
 		}
-	case in == reflect.Map && t == reflect.Struct:
-		if p, ok := v.(map[string]interface{}); ok {
-			c.cast(d.Addr().Interface(), p, map[string]interface{}{})
-			return p
+		if f, ok := c.structField(t.Field(i).Type, k); ok {
+			return f, true
 		}
 	}
-	return v
+	return reflect.StructField{}, false
 }
 
-func (c *Config) cast(o interface{}, m map[string]interface{}, discard map[string]interface{}) {
-	d := reflect.ValueOf(o).Elem()
-	for k, v := range m {
-		if _, ok := discard[k]; ok {
-			continue
+func (c *Config) field(name string) (reflect.Type, bool) {
+	c.mu.RLock()
+	t := reflect.TypeOf(c.target)
+	c.mu.RUnlock()
+	for _, k := range strings.Split(name, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
 		}
-		for i := 0; i < d.NumField(); i++ {
-			if n := strings.Split(d.Type().Field(i).Tag.Get("json"), ",")[0]; n == "-" || n != k {
-				continue
-			}
-			discard[k] = struct{}{}
-			m[k] = c.convert(d.Field(i), v)
+		if t == nil || t.Kind() != reflect.Struct {
+			return nil, false
 		}
-		if _, ok := discard[k]; ok {
-			continue
+		f, ok := c.structField(t, k)
+		if !ok {
+			return nil, false
 		}
-		for i := 0; i < d.NumField(); i++ {
-			if k != d.Type().Field(i).Name {
-				continue
+		t = f.Type
+	}
+	return t, t != nil
+}
+
+func (c *Config) known(name string) bool {
+	c.mu.RLock()
+	t, flags := reflect.TypeOf(c.target), c.featureFlags
+	c.mu.RUnlock()
+	if flags && (name == featureKey || strings.HasPrefix(name, featureKey+".")) {
+		return true
+	}
+	if b, rest, ok := c.binding(name); ok {
+		if rest == "" {
+			return true
+		}
+		t, name = b, rest
+	}
+	for _, k := range strings.Split(name, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return t != nil
+		}
+		f, ok := c.structField(t, k)
+		for i := 0; !ok && i < t.NumField(); i++ {
+			if n := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; strings.EqualFold(n, k) || (n == "" && strings.EqualFold(t.Field(i).Name, k)) {
+				f, ok = t.Field(i), true
 			}
-			discard[k] = struct{}{}
-			m[k] = c.convert(d.Field(i), v)
 		}
+		if !ok {
+			return false
+		}
+		t = f.Type
 	}
-	for i := 0; i < d.NumField(); i++ {
-		if t := strings.Split(d.Type().Field(i).Tag.Get("json"), ",")[0]; t != "" || d.Field(i).Kind() != reflect.Struct || !d.Type().Field(i).Anonymous || len(m) <= len(discard) {
-			continue
+	return true
+}
+
+func (c *Config) unknown(vars map[string]interface{}) error {
+	var keys []string
+	var errs []error
+	for k := range c.flatten("", vars, map[string]interface{}{}) {
+		if !c.known(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	names := c.names()
+	for _, k := range keys {
+		hint := ""
+		if s := c.suggest(k, names); s != "" {
+			hint = fmt.Sprintf(", did you mean %s?", s)
 		}
-		c.cast(reflect.New(d.Field(i).Type()).Interface(), m, discard)
+		c.notify(slog.LevelWarn, "unknown configuration key %s in %s%s", k, c.ConfigFile(), hint)
+		errs = append(errs, fmt.Errorf("%w (%s%s)", ErrUnknownKey, k, hint))
 	}
+	return errors.Join(errs...)
 }
 
 func (c *Config) merge(maps ...map[string]interface{}) map[string]interface{} {
 	m := make(map[string]interface{})
 	for _, t := range maps {
 		for k, v := range t {
-			if _, me := m[k]; me {
-				if m1, ok := m[k].(map[string]interface{}); ok {
-					if m2, is := v.(map[string]interface{}); is {
-						v = c.merge(m1, m2)
-					}
-				}
+			if old, me := m[k]; me {
+				v = c.element(old, v)
 			}
 			m[k] = v
 		}
@@ -131,26 +290,61 @@ func (c *Config) merge(maps ...map[string]interface{}) map[string]interface{} {
 func (c *Config) to(data ...map[string]interface{}) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.target == nil {
+	if c.target == nil && len(c.binds) == 0 {
 		return errNilTarget
 	}
-	combo := c.merge(data...)
-	if l, e := c.target.(locker); e {
-		l.Lock()
-		defer l.Unlock()
+	var combo map[string]interface{}
+	if c.inPlace && len(data) == 1 && data[0] != nil {
+		combo = data[0]
+	} else {
+		combo = c.merge(data...)
+	}
+	if c.target == nil {
+		return errors.Join(c.bound(combo)...)
+	}
+	dst := c.target
+	if c.swap {
+		var err error
+		if dst, err = c.fresh(); err != nil {
+			return err
+		}
+	} else {
+		defer c.writeLock(dst)()
+	}
+	errs := append(c.bound(combo), c.apply(dst, combo)...)
+	if c.swap && len(errs) == 0 {
+		c.current.Store(current{dst})
+	}
+	return errors.Join(errs...)
+}
+
+// Apply a merged configuration to a destination while the lock is held,
+// preferring its own ApplyConfig when it is an Applier.
+func (c *Config) apply(dst interface{}, combo map[string]interface{}) []error {
+	if a, ok := dst.(Applier); ok {
+		if err := a.ApplyConfig(combo); err != nil {
+			return []error{err}
+		}
+		return nil
 	}
-	c.cast(c.target, combo, map[string]interface{}{})
-	final, _ := json.Marshal(combo)
-	return json.Unmarshal(final, c.target)
+	if d := reflect.ValueOf(dst); d.Kind() != reflect.Ptr || d.IsNil() {
+		return []error{fmt.Errorf("%w into non-pointer %T", ErrCast, dst)}
+	} else if errs, ok := c.populateRoot(dst, combo); ok {
+		return errs
+	}
+	return c.populate(reflect.ValueOf(dst), combo, "")
 }
 
 func (c *Config) set(cursor map[string]interface{}, key string, value interface{}) {
-	keys := strings.Split(key, ".")
+	c.setPath(cursor, strings.Split(key, "."), value)
+}
+
+func (c *Config) setPath(cursor map[string]interface{}, keys []string, value interface{}) {
 	for i, k := range keys {
 		if i+1 == len(keys) {
 			cursor[k] = value
 		} else {
-			if _, ok := cursor[k]; !ok {
+			if _, ok := cursor[k].(map[string]interface{}); !ok {
 				cursor[k] = map[string]interface{}{}
 			}
 			cursor = cursor[k].(map[string]interface{})
@@ -158,158 +352,565 @@ func (c *Config) set(cursor map[string]interface{}, key string, value interface{
 	}
 }
 
-func (c *Config) parseEnvs() map[string]interface{} {
-	vars := make(map[string]interface{})
+func (c *Config) lookup(cursor map[string]interface{}, key string) (interface{}, bool) {
+	return c.lookupPath(cursor, strings.Split(key, "."))
+}
+
+func (c *Config) lookupPath(cursor map[string]interface{}, keys []string) (interface{}, bool) {
+	var v interface{} = cursor
+	for _, k := range keys {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[k]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, ok := c.position(k)
+			if !ok || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, len(keys) > 0
+}
+
+func (c *Config) option(cursor map[string]interface{}, key string, value interface{}) {
+	if t, ok := c.field(key); ok && t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		l, _ := c.lookup(cursor, key)
+		p, _ := l.([]interface{})
+		value = append(p, value)
+	}
+	c.set(cursor, key, value)
+}
+
+func (c *Config) unset(cursor map[string]interface{}, key string) {
+	keys := strings.Split(key, ".")
+	var parent interface{} = cursor
+	if len(keys) > 1 {
+		parent, _ = c.lookupPath(cursor, keys[:len(keys)-1])
+	}
+	if m, ok := parent.(map[string]interface{}); ok {
+		delete(m, keys[len(keys)-1])
+	}
+}
+
+func (c *Config) setting(name string) *setting {
+	for i := range c.settings {
+		if c.settings[i].Name == name {
+			return &c.settings[i]
+		}
+	}
+	return nil
+}
+
+func (c *Config) validate(data ...map[string]interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var errs []error
 	for _, s := range c.settings {
-		if s.Env == "" {
+		if len(s.Choices) == 0 {
 			continue
 		}
-		if v := os.Getenv(s.Env); len(v) > 0 {
-			c.set(vars, s.Name, v)
+		for _, m := range data {
+			if v, ok := c.lookup(m, s.Name); ok && v != nil && !s.Allows(v) {
+				c.unset(m, s.Name)
+				errs = append(errs, fmt.Errorf("%w (%s: %v)", errInvalidChoice, s.Name, v))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) deprecated(s setting, used string) {
+	if !s.Obsolete(used) {
+		return
+	}
+	c.mu.RLock()
+	r := c.setting(s.Name).Flags()
+	c.mu.RUnlock()
+	if !c.event(slog.LevelWarn, "deprecated configuration used", slog.String("used", used), slog.String("replacement", r)) {
+		c.notify(slog.LevelWarn, "%s is deprecated, use %s instead", used, r)
+	}
+}
+
+func (c *Config) fields(t reflect.Type, prefix string) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || strings.Count(prefix, ".") > maxDepth {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := strings.Split(f.Tag.Get("json"), ",")[0]
+		if n == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		} else if n == "" && f.Anonymous {
+			names = append(names, c.fields(f.Type, prefix)...)
+			continue
+		} else if n == "" {
+			n = f.Name
+		}
+		if prefix != "" {
+			n = prefix + "." + n
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !reflect.PtrTo(ft).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) && !reflect.PtrTo(ft).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+			names = append(names, c.fields(ft, n)...)
+		} else {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (c *Config) parseEnvs() map[string]interface{} {
+	vars := make(map[string]interface{})
+	origins := map[string]string{}
+	c.mu.RLock()
+	auto, empty, t := c.autoEnv, c.clearEmpty, reflect.TypeOf(c.target)
+	c.mu.RUnlock()
+	if auto {
+		for _, n := range c.fields(t, "") {
+			if v, ok := os.LookupEnv(c.envName(n)); len(v) > 0 || (ok && empty) {
+				c.set(vars, n, c.empty(v))
+				origins[n] = "env " + c.envName(n)
+			}
 		}
 	}
+	c.parseSettingEnvs(c.registrations(), empty, vars, origins)
+	c.provenance("env", origins)
 	return vars
 }
 
-func (c *Config) help(discontinue bool) {
+func (c *Config) parseSettingEnvs(settings []setting, empty bool, vars map[string]interface{}, origins map[string]string) {
+	for _, s := range settings {
+		for _, e := range append([]string{s.Env}, s.OldEnv...) {
+			if v, ok := os.LookupEnv(e); e != "" && (len(v) > 0 || (ok && empty)) {
+				c.deprecated(s, e)
+				c.set(vars, s.Name, c.empty(v))
+				origins[s.Name] = "env " + e
+				break
+			}
+		}
+	}
+}
+
+func (c *Config) help() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.description == "" {
-		return
+		return false
+	}
+	paint := c.painter()
+	c.printf("%s\n%s\n\t%s\n", paint(bold, "["+c.application()+"]"), paint(bold, "Description:"), c.description)
+	if m := c.metadata(); len(m) > 0 {
+		c.printf("\n")
+		for _, l := range m {
+			c.printf("\t%s %s\n", paint(bold, l[0]+":"), l[1])
+		}
+	}
+	c.printf("\n\n%s\n", paint(bold, "Flags:"))
+	c.printf("\t%s\n\t\t%s\n\n", paint(green, "help, -h, --help"), "display help information")
+	if c.version != "" {
+		c.printf("\t%s\n\t\t%s\n\n", paint(green, "-V, --version"), "display version information")
+	}
+	if !c.registered("--config") {
+		c.printf("\t%s\n\t\t%s\n\n", paint(green, "--config (GONF_CONFIG)"), "path to the configuration file")
+	}
+	if !c.registered("--set") {
+		c.printf("\t%s\n\t\t%s\n\n", paint(green, "--set key=value"), "override any key, repeatable")
+	}
+	if !c.registered("--check-config") {
+		c.printf("\t%s\n\t\t%s\n\n", paint(green, "--check-config"), "validate the configuration and exit")
+	}
+	if !c.registered("--dump-config") {
+		c.printf("\t%s\n\t\t%s\n\n", paint(green, "--dump-config"), "print the effective configuration and exit")
+	}
+	for _, g := range append([]string{""}, c.groups...) {
+		if g != "" {
+			c.printf("\n%s\n", paint(bold, g+":"))
+		}
+		for _, o := range c.settings {
+			if o.Group != g || o.Hidden {
+				continue
+			}
+			c.printf("%s\n", o.format(paint))
+			if a := o.Aliases(); a != "" {
+				c.printf("\t\tdeprecated: %s\n", paint(green, a))
+			}
+			c.printf("\n")
+		}
 	}
-	fmtPrintf("[%s]\nDescription:\n\t%s\n", appName, c.description)
-	fmtPrintf("\n\nFlags:\n")
-	fmtPrintf("\t%s\n\t\t%s\n\n", "help, -h, --help", "display help information")
+	var env bool
 	for _, o := range c.settings {
-		fmtPrintf("%s\n\n", o)
+		if o.Env == "" || o.Hidden {
+			continue
+		} else if !env {
+			c.printf("\n%s\n", paint(bold, "Environment:"))
+			env = true
+		}
+		e := c.pad(paint, green, o.Env, 30)
+		if v := os.Getenv(o.Env); v != "" && c.helpValues {
+			if c.sensitiveKey(o.Name) {
+				v = redacted
+			}
+			e = paint(green, o.Env) + "=" + c.pad(paint, yellow, v, 29-utf8.RuneCountInString(o.Env))
+		}
+		c.printf("\t%s\n\t\t%s\n\n", e, o.Description)
 	}
 	if len(c.examples) > 0 {
-		fmtPrintf("\nUsage:\n\n")
+		c.printf("\n%s\n\n", paint(bold, "Usage:"))
 	}
 	for _, e := range c.examples {
-		fmtPrintf("\t%s %s\n", appName, e)
+		c.printf("\t%s %s\n", c.application(), e.Usage)
+		if e.Description != "" {
+			c.printf("\t\t%s\n\n", e.Description)
+		}
+	}
+	c.printf("\n")
+	return true
+}
+
+func (c *Config) printVersion() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.version == "" {
+		return false
+	}
+	c.printf("%s %s\n", c.application(), c.version)
+	for _, l := range c.metadata()[1:] {
+		c.printf("\t%s: %s\n", l[0], l[1])
 	}
-	fmtPrintf("\n")
-	if discontinue {
-		exit(0)
+	if info, ok := readBuildInfo(); ok {
+		c.printf("\t%s\n", info.GoVersion)
+		for _, s := range info.Settings {
+			if strings.HasPrefix(s.Key, "vcs.") {
+				c.printf("\t%s=%s\n", s.Key, s.Value)
+			}
+		}
 	}
+	return true
 }
 
-func (c *Config) parseLong(i *int, m map[string]interface{}) {
+func (c *Config) parseLong(i *int, settings []setting, m map[string]interface{}, origins map[string]string) {
 	var y, greedy bool
 	argv := strings.SplitN(os.Args[*i], "=", 2)
-	for _, s := range c.settings {
+	for _, s := range settings {
 		if y, greedy = s.Match(argv[0]); !y {
 			continue
 		}
+		c.deprecated(s, argv[0])
+		origins[s.Name] = "option " + argv[0]
 		switch {
 		case len(argv) == 1 && *i+1 < len(os.Args) && os.Args[*i+1] != "--" && (!strings.HasPrefix(os.Args[*i+1], "-") || greedy):
 			*i++
-			c.set(m, s.Name, os.Args[*i])
+			c.option(m, s.Name, os.Args[*i])
 		case len(argv) == 2 && argv[1] != "":
-			c.set(m, s.Name, argv[1])
+			c.option(m, s.Name, argv[1])
 		default:
-			c.set(m, s.Name, true)
+			c.option(m, s.Name, true)
 		}
 	}
 }
 
-func (c *Config) parseShort(i *int, m map[string]interface{}) {
+func (c *Config) parseShort(i *int, settings []setting, m map[string]interface{}, origins map[string]string) {
 	var y, greedy bool
-	a := strings.TrimPrefix(os.Args[*i], "-")
+	a := []rune(strings.TrimPrefix(os.Args[*i], "-"))
 	for ci, cl := range a {
-		for _, s := range c.settings {
+		for _, s := range settings {
 			if y, greedy = s.Match("-" + string(cl)); !y {
 				continue
 			}
+			c.deprecated(s, "-"+string(cl))
+			origins[s.Name] = "option -" + string(cl)
 			switch {
 			case ci+1 >= len(a) && *i+1 < len(os.Args) && os.Args[*i+1] != "--" && (!strings.HasPrefix(os.Args[*i+1], "-") || greedy):
 				*i++
-				c.set(m, s.Name, os.Args[*i])
+				c.option(m, s.Name, os.Args[*i])
+			case ci+1 < len(a) && a[ci+1] == '=' && ci+2 < len(a):
+				c.option(m, s.Name, string(a[ci+2:]))
+				return
+			case ci+1 < len(a) && a[ci+1] == '=':
+				c.option(m, s.Name, true)
+				return
 			case ci+1 < len(a) && greedy:
-				c.set(m, s.Name, a[ci+1:])
+				c.option(m, s.Name, string(a[ci+1:]))
 				return
 			default:
-				c.set(m, s.Name, true)
+				c.option(m, s.Name, true)
 			}
 		}
 	}
 }
 
+func (c *Config) registered(option string) bool {
+	return c.matched(c.settings, option)
+}
+
+// Report whether any of a copy of the settings matches the option, for
+// callers that do not hold the lock.
+func (c *Config) matched(settings []setting, option string) bool {
+	for _, s := range settings {
+		if y, _ := s.Match(option); y {
+			return true
+		}
+	}
+	return false
+}
+
+// Return a copy of the registered settings, so that they may be read without
+// the lock while Add is called concurrently.
+func (c *Config) registrations() []setting {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]setting{}, c.settings...)
+}
+
+func (c *Config) parseConfig(i *int, settings []setting) bool {
+	argv := strings.SplitN(os.Args[*i], "=", 2)
+	if argv[0] != "--config" || c.matched(settings, argv[0]) {
+		return false
+	}
+	f := ""
+	if len(argv) == 2 {
+		f = argv[1]
+	} else if *i+1 < len(os.Args) {
+		*i++
+		f = os.Args[*i]
+	}
+	c.mu.Lock()
+	c.override = f
+	c.mu.Unlock()
+	return true
+}
+
+func (c *Config) parseSet(i *int, settings []setting, m map[string]interface{}, origins map[string]string) bool {
+	argv := strings.SplitN(os.Args[*i], "=", 2)
+	if argv[0] != "--set" || c.matched(settings, argv[0]) {
+		return false
+	}
+	kv := ""
+	if len(argv) == 2 {
+		kv = argv[1]
+	} else if *i+1 < len(os.Args) {
+		*i++
+		kv = os.Args[*i]
+	}
+	if p := strings.SplitN(kv, "=", 2); len(p) != 2 || p[0] == "" {
+		c.mu.Lock()
+		c.warning("ignoring --set %q, expected key=value", kv)
+		c.mu.Unlock()
+	} else {
+		c.option(m, p[0], p[1])
+		origins[p[0]] = "option --set"
+	}
+	return true
+}
+
 func (c *Config) parseOptions() map[string]interface{} {
 	vars := map[string]interface{}{}
+	args := []string{}
+	origins := map[string]string{}
+	settings := c.registrations()
+	c.mu.Lock()
+	c.override, c.check, c.dump, c.halted = "", false, false, nil
+	c.mu.Unlock()
 	for i := 0; i < len(os.Args); i++ {
 		if arg := os.Args[i]; arg == "--" {
+			args = append(args, os.Args[i+1:]...)
 			break
 		} else if arg == "help" || arg == "-h" || arg == "--help" {
-			c.help(true)
+			if c.help() {
+				c.halt(0, ErrHelp)
+			}
+		} else if arg == "-V" || arg == "--version" {
+			if c.printVersion() {
+				c.halt(0, ErrHelp)
+			}
+		} else if c.parseConfig(&i, settings) || c.parseSet(&i, settings, vars, origins) {
+			continue
+		} else if arg == "--check-config" && !c.matched(settings, arg) {
+			c.mu.Lock()
+			c.check = true
+			c.mu.Unlock()
+			continue
+		} else if arg == "--dump-config" && !c.matched(settings, arg) {
+			c.mu.Lock()
+			c.dump = true
+			c.mu.Unlock()
+			continue
 		} else if len(arg) == 1 || !strings.HasPrefix(arg, "-") {
+			if i > 0 {
+				args = append(args, arg)
+			}
 			continue
 		}
 		if arg := os.Args[i]; strings.HasPrefix(arg, "--") {
-			c.parseLong(&i, vars)
+			c.parseLong(&i, settings, vars, origins)
 		} else {
-			c.parseShort(&i, vars)
+			c.parseShort(&i, settings, vars, origins)
 		}
 	}
+	c.mu.Lock()
+	c.args = args
+	c.mu.Unlock()
+	c.provenance("option", origins)
 	return vars
 }
 
-func (c *Config) comment(data []byte) []byte {
-	re := regexp.MustCompile(`(?:/\*[^*]*\*+(?:[^/*][^*]*\*+)*/|//[^\n]*(?:\n|$)|#[^\n]*(?:\n|$))|("[^"\\]*(?:\\[\S\s][^"\\]*)*"|'[^'\\]*(?:\\[\S\s][^'\\]*)*'|[\S\s][^/"'\\]*)`)
-	return re.ReplaceAll(data, []byte("$1"))
+// Run fn until it returns or the context is done, whichever comes first.  The
+// function is not interrupted, and keeps running in the background after the
+// context is cancelled, so it must not write to state shared beyond the call.
+func (c *Config) withContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
 }
 
-func (c *Config) readFile() (map[string]interface{}, error) {
+func (c *Config) readFile(ctx context.Context) (map[string]interface{}, error) {
 	vars := make(map[string]interface{})
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	var fi os.FileInfo
+	var data []byte
 	modTime := c.configModified
-	if fi, err := stat(c.configFile); err == nil {
-		if modTime = fi.ModTime(); c.configModified.Equal(modTime) {
+	name := c.configFile
+	if err := c.withContext(ctx, func() (e error) { fi, e = c.statFile(name); return }); err == nil {
+		if modTime = fi.ModTime(); !modTime.IsZero() && c.configModified.Equal(modTime) {
 			return vars, errNoChanges
 		}
+	} else if ctx.Err() != nil {
+		return vars, err
+	} else if !os.IsNotExist(err) {
+		c.warning("unable to stat %s: %v", name, err)
 	}
-	data, err := readfile(c.configFile)
+	err := c.withContext(ctx, func() (e error) { data, e = c.read(name); return })
 	if err != nil {
 		return vars, err
+	} else if err = c.withContext(ctx, func() error { return c.verify(name, data) }); err != nil {
+		return vars, err
+	}
+	if data, c.encrypted, err = c.decrypt(data); err != nil {
+		return vars, err
 	}
 	c.configModified = modTime
-	err = json.Unmarshal(c.comment(data), &vars)
+	c.comments, c.lines = nil, nil
+	if !strings.EqualFold(filepath.Ext(name), ".plist") {
+		c.comments = c.extractComments(data)
+		if c.schema != nil {
+			c.lines = c.locate(data)
+		}
+	}
+	if vars, err = c.decode(name, data); err != nil {
+		return vars, fmt.Errorf("%w %s: %v", ErrParse, name, err)
+	} else {
+		if c.sources == nil {
+			c.sources = map[string]map[string]string{}
+		}
+		c.sources["file"] = c.renameOrigins(c.keyMapper, c.origins(name, vars, map[string]string{}))
+	}
 	return vars, err
 }
 
-func (c *Config) parseFiles(filenames ...string) (map[string]interface{}, error) {
+func (c *Config) parseFiles(ctx context.Context, filenames ...string) (map[string]interface{}, error) {
 	vars := make(map[string]interface{})
+	c.mu.Lock()
+	c.system, c.systemFile, c.systemSig = nil, "", ""
+	c.encrypted, c.references = false, nil
+	c.mu.Unlock()
 	for _, f := range filenames {
 		if filepath.IsAbs(f) {
 			c.mu.Lock()
 			c.configFile = f
 			c.mu.Unlock()
-			if vars, err := c.readFile(); err == nil {
-				return vars, nil
+			vars, err := c.readFile(ctx)
+			c.consulted(f, err)
+			if err == nil {
+				return c.withDropins(ctx, vars)
+			} else if c.sealed(err) || errors.Is(err, ErrParse) || errors.Is(err, ErrChecksum) {
+				return vars, err
 			}
 		} else {
-			for _, p := range paths {
+			sys, err := c.readSystem(ctx, f)
+			if err != nil {
+				return vars, err
+			}
+			c.mu.Lock()
+			c.system, c.systemFile, c.systemSig = sys.vars, f, sys.sig
+			c.mu.Unlock()
+			c.provenance("system", sys.origins)
+			for _, p := range c.searchPaths() {
+				name := c.discover(ctx, filepath.Join(p, f))
+				c.mu.Lock()
+				c.configFile = name
+				c.mu.Unlock()
+				vars, err := c.readFile(ctx)
+				c.consulted(name, err)
+				if err == nil {
+					return c.withDropins(ctx, c.merge(sys.vars, vars))
+				} else if c.sealed(err) || errors.Is(err, ErrParse) || errors.Is(err, ErrChecksum) {
+					return vars, err
+				}
+			}
+			if len(sys.found) > 0 {
 				c.mu.Lock()
-				c.configFile = filepath.Join(p, f)
+				c.configFile = sys.found[len(sys.found)-1]
 				c.mu.Unlock()
-				if vars, err := c.readFile(); err == nil {
-					return vars, nil
+				if vars, err := c.readFile(ctx); err == nil {
+					return c.withDropins(ctx, c.merge(sys.vars, vars))
 				}
+				return c.withDropins(ctx, sys.vars)
 			}
 		}
+		if err := ctx.Err(); err != nil {
+			return vars, err
+		}
+	}
+	search, save := c.searchPaths(), filenames[0]
+	for i := len(filenames) - 1; i >= 0; i-- {
+		if !filepath.IsAbs(filenames[i]) {
+			save = filenames[i]
+		}
+	}
+	dir := search[len(search)-1]
+	if c.isPortable() {
+		dir = binPath
 	}
 	c.mu.Lock()
-	c.configFile = filepath.Join(paths[len(paths)-1], filenames[0])
+	c.configFile = filepath.Join(dir, save)
+	c.system, c.systemFile, c.systemSig = nil, "", ""
+	dry := c.check || c.dryRun || c.fsys() != nil
 	c.mu.Unlock()
-	return vars, c.Save()
+	if dry {
+		return vars, nil
+	} else if err := c.Save(); err != nil {
+		return vars, fmt.Errorf("%w %v", ErrNoConfigFile, err)
+	}
+	return vars, nil
 }
 
 // Set the configuration target using this method.
 func (c *Config) Target(t interface{}) {
 	c.mu.Lock()
-	c.target = t
+	c.target, c.baseline = t, nil
+	c.current.Store(current{})
 	c.mu.Unlock()
 }
 
@@ -318,40 +919,195 @@ func (c *Config) Target(t interface{}) {
 // optional, will be used to generate help information.
 //
 // The name must not be empty, and a non-empty environment variable or at least
-// one command line option must be supplied. If the parameters are invalid,
-// or the name has already been registered, an error will be returned. Finally
-// if the name has bad syntax for child properties an error will be returned.
+// one command line option must be supplied, unless an EnvPrefix has been set.
+// If the parameters are invalid, or the name has already been registered, an
+// error will be returned. Finally if the name has bad syntax for child
+// properties an error will be returned.
 //
 // Duplicate environment variables or command line options are accepted.
 //
+// When the name refers to a slice, each occurrence of a command line option
+// is accumulated in order instead of overwriting the previous value.
+//
 // As defined by some POSIX getopt implementations, a colon suffix (:) is used
 // to define explicit notation for greedy parameters, which can help deal with
 // single-character command line options when the supplied value matches
 // another registered single-character command line option.
+//
+// Single-character command line options accept values as `-kvalue`, `-k value`,
+// or `-k=value`.
 func (c *Config) Add(name, description, env string, options ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if name == "" {
 		return errEmptyName
-	} else if env == "" && len(options) == 0 {
+	} else if env == "" && len(options) == 0 && c.envPrefix == "" {
 		return errNoEnvOptions
 	} else if name == "." || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
 		return errBadNameSyntax
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
 	for _, s := range c.settings {
 		if s.Name == name {
 			return errConflictingAdd
 		}
 	}
-	c.settings = append(c.settings, setting{
+	s := setting{
 		Name:        name,
 		Description: description,
 		Env:         env,
 		Options:     options,
-	})
+	}
+	if env == "" && c.envPrefix != "" {
+		s.Env, s.Prefixed = c.prefixed(name), true
+	}
+	c.settings = append(c.settings, s)
+	c.late(s)
+	return nil
+}
+
+func (c *Config) prefixed(name string) string {
+	if c.keyCase != "" {
+		name = strings.Join(c.words(strings.Replace(name, ".", "_", -1)), "_")
+	}
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(c.envPrefix + "_" + name))
+}
+
+func (c *Config) envName(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.envPrefix != "" {
+		return c.prefixed(name)
+	} else if c.keyCase != "" {
+		name = strings.Join(c.words(strings.Replace(name, ".", "_", -1)), "_")
+	}
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// Bind an environment variable to every field of the target, including those
+// that were never registered with Add, so that any value may be overridden.
+// Names are derived from the json tag or property name of each field in the
+// same way as EnvPrefix, and registered settings take precedence.
+func (c *Config) AutoEnv() {
+	c.mu.Lock()
+	c.autoEnv = true
+	c.mu.Unlock()
+}
+
+// Automatically bind an environment variable named by the prefix and the
+// registered name in upper case, with dots replaced by underscores (eg.
+// `MYAPP_SERVER_PORT` for `server.port`), to every setting that does not
+// supply its own.  With a prefix set, Add no longer requires an environment
+// variable or command line option.
+func (c *Config) EnvPrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.envPrefix = prefix
+	for i, s := range c.settings {
+		if s.Env != "" && !s.Prefixed {
+			continue
+		} else if prefix == "" {
+			c.settings[i].Env, c.settings[i].Prefixed = "", false
+		} else {
+			c.settings[i].Env, c.settings[i].Prefixed = c.prefixed(s.Name), true
+		}
+	}
+}
+
+// Restrict a registered name to a set of allowed values.  Any other value
+// supplied by a file, environment variable, or command line option will be
+// discarded with an error, and the choices are included in the help output.
+func (c *Config) Choices(name string, choices ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.setting(name)
+	if s == nil {
+		return errNotRegistered
+	}
+	s.Choices = choices
 	return nil
 }
 
+// Assign registered names to a named group, which is rendered as a separate
+// section of the help output in the order groups were first assigned.
+func (c *Config) Group(group string, names ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range names {
+		if c.setting(n) == nil {
+			return errNotRegistered
+		}
+	}
+	if group == "" {
+		return errEmptyName
+	}
+	for _, n := range names {
+		c.setting(n).Group = group
+	}
+	for _, g := range c.groups {
+		if g == group {
+			return nil
+		}
+	}
+	c.groups = append(c.groups, group)
+	return nil
+}
+
+// Hide registered names from help and generated documentation, while still
+// parsing them, for internal or experimental settings.
+func (c *Config) Hidden(names ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range names {
+		if c.setting(n) == nil {
+			return errNotRegistered
+		}
+	}
+	for _, n := range names {
+		c.setting(n).Hidden = true
+	}
+	return nil
+}
+
+// Register a deprecated environment variable or command line options as
+// aliases of an existing name, so that renamed settings continue to work.
+// Using them will log a warning naming the replacement, and help lists them
+// as deprecated.
+func (c *Config) Deprecated(name, env string, options ...string) error {
+	if env == "" && len(options) == 0 {
+		return errNoEnvOptions
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.setting(name)
+	if s == nil {
+		return errNotRegistered
+	}
+	a := setting{Name: name, OldOptions: options}
+	if env != "" {
+		a.OldEnv = []string{env}
+	}
+	s.OldEnv = append(s.OldEnv, a.OldEnv...)
+	s.OldOptions = append(s.OldOptions, options...)
+	c.late(a)
+	return nil
+}
+
+// Return the application name, which defaults to the name of the executable.
+func (c *Config) application() string {
+	if n, _ := c.name.Load().(string); n != "" {
+		return n
+	}
+	return appName
+}
+
+// Override the application name derived from the executable, which names the
+// configuration files and drop-in directory searched for, and identifies the
+// application in help and generated documentation, so that renamed binaries,
+// symbolic links, and test runners locate the same files.
+func (c *Config) Name(name string) {
+	c.name.Store(name)
+}
+
 // To enable automated help, set a non-empty description.
 func (c *Config) Description(d string) {
 	c.mu.Lock()
@@ -359,14 +1115,32 @@ func (c *Config) Description(d string) {
 	c.mu.Unlock()
 }
 
+// To enable automated version output with `-V` or `--version`, set a non-empty
+// version.  The output includes build metadata when it is available.
+func (c *Config) Version(v string) {
+	c.mu.Lock()
+	c.version = v
+	c.mu.Unlock()
+}
+
+// Include the current value of each environment variable in the help output,
+// with those of sensitive settings redacted.  It is disabled by default, since
+// help is often pasted into tickets and chat where values should not appear.
+func (c *Config) HelpValues(enabled bool) {
+	c.mu.Lock()
+	c.helpValues = enabled
+	c.mu.Unlock()
+}
+
 // Provides a registration for custom examples of command line use cases,
-// automatically prefixed by the application name.
-func (c *Config) Example(example string) {
-	if example == "" {
+// automatically prefixed by the application name, with an optional short
+// description printed beneath it.
+func (c *Config) Example(usage string, description ...string) {
+	if usage == "" {
 		return
 	}
 	c.mu.Lock()
-	c.examples = append(c.examples, example)
+	c.examples = append(c.examples, example{usage, strings.Join(description, " ")})
 	c.mu.Unlock()
 }
 
@@ -375,7 +1149,8 @@ func (c *Config) Example(example string) {
 // and json file data onto the configuration target.
 //
 // A POSIX compatible getopt command line parser is run first to deal with
-// optional help flags and terminate prior to any file system access.
+// optional help flags and terminate prior to any file system access, or
+// return ErrHelp when the ErrorHandling is ContinueOnError.
 //
 // Custom paths may be supplied, both relative to the system paths or absolute
 // for full control.  Empty names will be discarded and ignored.  The default
@@ -384,6 +1159,28 @@ func (c *Config) Example(example string) {
 // the default userspace path (unless the file name is absolute) to save the
 // defaults on the configuration target.
 //
+// The built-in `--config` command line option, or `GONF_CONFIG` environment
+// variable, overrides the search with a single path, and returns an error if
+// the file cannot be read.  The command line option is ignored if an option
+// with the same name has been registered.
+//
+// Relative names are also checked in each of the system paths from
+// `$XDG_CONFIG_DIRS`, where found files are merged by priority beneath the
+// user file.  If only system files exist, they are used without saving
+// defaults to the user path.
+//
+// On darwin, property list files named after the application are also checked
+// in `~/Library/Preferences` and `/Library/Preferences`, and any file with a
+// .plist extension is parsed as a property list.
+//
+// Relative names with a supported extension also match the same name with any
+// other supported extension, in the priority order json then property list,
+// and a warning is logged when several exist side by side.
+//
+// Once a file is found, every json file in a drop-in directory named after the
+// application with a .d suffix alongside it (eg. `app/app.d/`) is merged over
+// it in lexical order, matching the convention used by systemd and nginx.
+//
 // Data loaded from a file is applied directly and follows the same rules as
 // json unmarshal.  This means tags first, then property names, finally any
 // non-ambiguous properties matching anonynous composite structures.  File
@@ -394,12 +1191,12 @@ func (c *Config) Example(example string) {
 // are found they will be safely filtered from the file (unless inside quotes).
 //
 // Both command line options and environment variables are converted to the
-// configuration targets expected types using reflection prior to being run
-// through json unmarshal.
+// configuration targets expected types using reflection as they are applied.
 //
-// If any steps fail, the errors will be collected and aggregated for the
+// If any steps fail, the errors will be collected into a LoadError for the
 // response, however the system will still make a complete attempt to load
-// which means the errors may be treated as non-critical.
+// which means the errors may be treated as non-critical.  Each cause may be
+// identified with errors.Is, such as ErrParse or ErrUnknownKey.
 //
 // The operation is concurrently safe, and performs a lock prior to running
 // any steps that touch its own properties.  If the target supports mutex
@@ -408,62 +1205,372 @@ func (c *Config) Example(example string) {
 // Finally, it returns with an aggregate of any errors that were encountered
 // giving the developer the option of printing them or terminating.
 func (c *Config) Load(filenames ...string) error {
-	opts := c.parseOptions()
+	return c.LoadContext(context.Background(), filenames...)
+}
+
+// Identical to Load, except that file system operations respect cancellation
+// and deadlines of the supplied context, so that a slow or unresponsive mount
+// cannot hang the application indefinitely.  If the context is done before a
+// file has been read, the defaults will not be saved and the context error is
+// returned alongside any other errors.
+func (c *Config) LoadContext(ctx context.Context, filenames ...string) error {
 	for i := len(filenames) - 1; i >= 0; i-- {
 		if filenames[i] == "" {
 			filenames = append(filenames[:i], filenames[i+1:]...)
 		}
 	}
-	files, err := c.parseFiles(append(filenames, filepath.Join(appName, appName+".json"))...)
-	if e := c.to(files, c.parseEnvs(), opts); e != nil {
+	return c.load(ctx, func() (map[string]interface{}, error) {
+		c.mu.Lock()
+		if c.override == "" {
+			c.override = os.Getenv("GONF_CONFIG")
+		}
+		override := c.override
+		c.mu.Unlock()
+		if override == "" {
+			return c.parseFiles(ctx, append(filenames, c.defaultFiles()...)...)
+		}
+		c.mu.Lock()
+		c.configFile = override
+		c.mu.Unlock()
+		files, err := c.readFile(ctx)
+		c.consulted(override, err)
+		if err == nil {
+			return c.withDropins(ctx, files)
+		} else if os.IsNotExist(err) {
+			err = fmt.Errorf("%w %v", ErrNoConfigFile, err)
+		}
+		return files, err
+	})
+}
+
+// Run every step of Load, acquiring file data from the supplied function
+// once command line options have been parsed.
+func (c *Config) load(ctx context.Context, read func() (map[string]interface{}, error)) error {
+	if err := c.thawed("load"); err != nil {
+		return err
+	}
+	c.mu.RLock()
+	remembered := c.baseline != nil
+	c.mu.RUnlock()
+	if !remembered {
+		baseline := c.snapshot()
+		c.mu.Lock()
+		c.baseline = baseline
+		c.mu.Unlock()
+	}
+	w := c.stopwatch(false)
+	var defaults, opts, files, envs map[string]interface{}
+	var terr, err, rerr error
+	w.time("defaults", func() map[string]interface{} {
+		mdefaults, merr := c.mounted()
+		defaults, terr = c.harvest()
+		defaults, terr = c.merge(c.builtins(), mdefaults, defaults), errors.Join(merr, terr)
+		return defaults
+	})
+	c.mu.Lock()
+	c.started, c.added = true, nil
+	c.mu.Unlock()
+	w.time("options", func() map[string]interface{} { opts = c.parseOptions(); return opts })
+	c.mu.RLock()
+	halted := c.halted
+	c.mu.RUnlock()
+	if halted != nil {
+		return halted
+	}
+	c.mu.Lock()
+	for _, l := range []string{"api", "file", "system", "dropin"} {
+		delete(c.sources, l)
+	}
+	c.mu.Unlock()
+	w.time("files", func() map[string]interface{} { files, err = read(); return files })
+	w.time("references", func() map[string]interface{} {
+		files, rerr = c.resolveReferences(ctx, files)
+		return nil
+	})
+	files = c.migrate(c.mapKeys(files))
+	serr := c.checkSchema(files)
+	w.time("env", func() map[string]interface{} { envs = c.parseEnvs(); return envs })
+	defaults, envs, opts = c.mapKeys(defaults), c.mapKeys(envs), c.mapKeys(opts)
+	defaults, files, envs, opts = c.normalize(defaults), c.normalize(files), c.normalize(envs), c.normalize(opts)
+	layers, xerr := c.expand(nil, defaults, files, envs, opts)
+	defaults, files, envs, opts = layers[0], layers[1], layers[2], layers[3]
+	defaults = c.merge(defaults, c.derive(c.merge(defaults, files, envs, opts), files, envs, opts))
+	uerr := errors.Join(c.unknown(files), c.unknownEnvs())
+	c.mu.RLock()
+	if !c.strict && !c.check {
+		uerr = nil
+	}
+	c.mu.RUnlock()
+	combo := []map[string]interface{}{defaults, files, envs, opts}
+	c.mu.Lock()
+	c.merged = nil
+	if c.inPlace {
+		c.merged = map[string]interface{}{}
+		for _, m := range combo {
+			c.mergeInto(c.merged, m)
+		}
+		combo = []map[string]interface{}{c.merged}
+	}
+	c.mu.Unlock()
+	if len(combo) > 1 {
+		c.remember(c.merge(combo...))
+	}
+	c.toggled(false)
+	err = c.collect(terr, err, rerr, serr, xerr, uerr, c.missing(files, envs, opts), c.validate(defaults, files, envs, opts), c.to(combo...))
+	c.flush()
+	c.explain(defaults)
+	c.refresh()
+	w.stop()
+	c.loaded(false, err)
+	c.event(c.level(err), "configuration loaded", c.errorAttr([]slog.Attr{
+		slog.String("file", c.ConfigFile()),
+		slog.Int("files", len(c.flatten("", files, map[string]interface{}{}))),
+		slog.Int("env", len(c.flatten("", envs, map[string]interface{}{}))),
+		slog.Int("options", len(c.flatten("", opts, map[string]interface{}{}))),
+	}, err)...)
+	c.mu.RLock()
+	check, dump := c.check, c.dump
+	c.mu.RUnlock()
+	if dump {
+		c.Dump(c.writer(), "text")
+	}
+	if check {
+		c.report(err)
+	}
+	if check || dump {
+		code, reason := 0, ErrHelp
 		if err != nil {
-			return fmt.Errorf("%s\n%s", err.Error(), e.Error())
+			code, reason = 1, err
+		}
+		if e := c.halt(code, reason); e != nil {
+			return e
 		}
-		return e
 	}
 	return err
 }
 
-// Used to manually reload changes from the configuration file, if the file has
-// been modified since the last attempt to load it.
+// Used to manually reload changes from the configuration file, if the file or
+// any of its drop-in files have been modified since the last attempt to load.
+//
+// A diff of the changed keys is supplied to any OnReload callbacks, and
+// bursts of calls are coalesced when Debounce has been set.
 func (c *Config) Reload() error {
+	return c.debounced(c.reload)
+}
+
+func (c *Config) reload() error {
 	if c.ConfigFile() == "" {
 		return errEmptyConfig
+	} else if err := c.thawed("reload"); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	w := c.stopwatch(true)
+	var v map[string]interface{}
+	var err, derr error
+	w.time("files", func() map[string]interface{} {
+		var d map[string]interface{}
+		var sig string
+		d, sig, derr = c.readDropins(ctx)
+		c.mu.Lock()
+		if sig != c.dropins {
+			c.configModified, c.dropins = time.Time{}, sig
+		}
+		f := c.systemFile
+		c.mu.Unlock()
+		if f != "" {
+			sys, serr := c.readSystem(ctx, f)
+			c.mu.Lock()
+			if serr != nil {
+				c.configModified, derr = time.Time{}, errors.Join(derr, serr)
+			} else if sys.sig != c.systemSig {
+				c.configModified, c.system, c.systemSig = time.Time{}, sys.vars, sys.sig
+			}
+			c.mu.Unlock()
+			if serr == nil {
+				c.provenance("system", sys.origins)
+			}
+		}
+		if v, err = c.readFile(ctx); err == nil {
+			c.mu.RLock()
+			v = c.merge(c.system, v, d)
+			c.mu.RUnlock()
+		}
+		return v
+	})
+	if err == nil || err == errNoChanges {
+		if added := c.parseAdded(); len(added) > 0 {
+			if err == errNoChanges {
+				err, v = nil, map[string]interface{}{}
+			}
+			v = c.merge(v, added)
+		}
+	}
+	if err != errNoChanges {
+		c.reloading()
+		defer c.daemon("READY=1")
 	}
-	v, err := c.readFile()
 	if err == nil && len(v) > 0 {
-		return c.to(v)
+		before := c.snapshot()
+		var rerr error
+		w.time("references", func() map[string]interface{} {
+			v, rerr = c.resolveReferences(ctx, v)
+			return nil
+		})
+		v = c.migrate(c.mapKeys(v))
+		serr := c.checkSchema(v)
+		v = c.normalize(v)
+		c.mu.RLock()
+		base, inPlace := c.clone(c.merged), c.inPlace
+		c.mu.RUnlock()
+		layers, xerr := c.expand(base, v)
+		if inPlace {
+			base = c.mergeInto(base, layers[0])
+		} else {
+			base = c.merge(base, layers[0])
+		}
+		v = c.merge(layers[0], c.derive(base, layers[0]))
+		c.unknown(v)
+		verr := errors.Join(serr, xerr, c.validate(v))
+		c.mu.RLock()
+		transactional := c.transactional
+		c.mu.RUnlock()
+		if transactional {
+			if err = errors.Join(derr, rerr, verr); err == nil {
+				err = c.commit(v)
+			}
+			if err == nil {
+				c.remember(v)
+				c.changed(c.diff(before, c.snapshot()))
+			}
+		} else {
+			c.remember(v)
+			if err = c.to(v); err == nil {
+				c.changed(c.diff(before, c.snapshot()))
+			}
+			err = errors.Join(derr, rerr, verr, err)
+		}
+	}
+	c.flush()
+	if err != errNoChanges {
+		c.explain(nil)
+		w.stop()
+	}
+	c.loaded(true, err)
+	if err == nil || err == errNoChanges {
+		return err
+	} else if !c.event(slog.LevelError, "configuration reload failed", slog.String("file", c.ConfigFile()), slog.String("error", err.Error())) {
+		c.notify(slog.LevelError, "configuration reload failed: %v", err)
 	}
 	return err
 }
 
+// Register a callback to receive the set of changed keys after each successful
+// Reload.  If the target supplies Info and Debug logging functions, the changes
+// will also be logged.
+func (c *Config) OnReload(fn func(ChangeSet)) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	c.onReload = append(c.onReload, fn)
+	c.mu.Unlock()
+}
+
 // For cases where you want to persist changes to the configuration target,
-// this function will save an intended readable json file to the ConfigFile
+// this function will save an indented readable file to the ConfigFile
 // identified during Load, or it will return an error if any step fails.
+//
+// The file is written to a temporary file in the same directory, synced, and
+// then renamed over the original, so that a crash cannot leave a truncated
+// configuration file.  Unless Permissions have been supplied, those of an
+// existing file are preserved, otherwise new files are only accessible by the
+// owner.
+//
+// The file is saved in the Format supplied, or otherwise the format matching
+// its extension, with a fallback of json.  Comments found when the json file
+// was loaded are restored above the keys they preceded.  Files that were
+// encrypted when loaded, or all files once a Key is supplied, are encrypted.
 func (c *Config) Save() error {
+	err := c.save()
+	msg := "configuration saved"
+	if err != nil {
+		msg = "configuration save failed"
+	}
+	c.event(c.level(err), msg, c.errorAttr([]slog.Attr{slog.String("file", c.ConfigFile())}, err)...)
+	return err
+}
+
+func (c *Config) save() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.configFile == "" {
 		return errEmptyConfig
+	} else if c.fsys() != nil {
+		return errReadOnly
 	}
-	mkdirall(filepath.Dir(c.configFile), os.ModePerm)
-	f, err := create(c.configFile)
+	data, err := c.encode(c.configFile)
 	if err != nil {
 		return err
 	}
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "\t")
-	if err := enc.Encode(c.target); err != nil {
-		f.Close()
+	dir := filepath.Dir(c.configFile)
+	dirMode := os.ModePerm
+	if c.dirMode != 0 {
+		dirMode = c.dirMode
+	}
+	mkdirall(dir, dirMode)
+	if err := c.replace(c.configFile, data); err != nil {
 		return err
+	} else if c.checksums {
+		sum := sha256.Sum256(data)
+		if err := c.replace(c.configFile+".sha256", []byte(fmt.Sprintf("%x  %s\n", sum, filepath.Base(c.configFile)))); err != nil {
+			return err
+		}
 	}
-	return f.Close()
+	if d, e := os.Open(dir); e == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// Atomically replace a file with data through a temporary file beside it,
+// applying the configured permissions and ownership, while the lock is held.
+func (c *Config) replace(name string, data []byte) error {
+	f, err := createTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if c.fileMode != 0 {
+		err = f.Chmod(c.fileMode)
+	} else if fi, e := stat(name); e == nil {
+		f.Chmod(fi.Mode().Perm())
+	}
+	if c.owner && goos != "windows" && err == nil {
+		err = chown(f.Name(), c.uid, c.gid)
+	}
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = rename(f.Name(), name)
+	}
+	if err != nil {
+		remove(f.Name())
+	}
+	return err
 }
 
 // If the instance has a non-empty Description the help will be printed,
 // however the application will not be terminated.
 func (c *Config) Help() {
-	c.help(false)
+	c.help()
 }
 
 // After Load this will return the full path to the preferred file.
@@ -473,3 +1580,12 @@ func (c *Config) ConfigFile() string {
 	c.mu.RUnlock()
 	return cf
 }
+
+// After Load this will return any positional arguments that were not consumed
+// by registered options, followed by everything after the "--" terminator, so
+// they may be forwarded to child processes.
+func (c *Config) Args() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string{}, c.args...)
+}
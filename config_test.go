@@ -1,11 +1,15 @@
 package gonf
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -73,6 +77,9 @@ type mockConfig struct {
 
 	EnvOverrideFile   string
 	OptionOverrideEnv string
+
+	OptionSlice   []string
+	OptionNumbers []int
 }
 
 var mockError error = errors.New("mock error")
@@ -132,10 +139,37 @@ func TestDescription(_ *testing.T) {
 	c.Description("")
 }
 
-func TestExample(_ *testing.T) {
+func TestName(t *testing.T) {
+	c := &Config{}
+	if c.application() != appName {
+		t.Errorf("failed to default to the executable name, %s...", c.application())
+	}
+	c.Name("myapp")
+	if f := c.defaultFiles(); f[0] != filepath.Join("myapp", "myapp.json") || !strings.HasPrefix(c.Man(), ".TH MYAPP 1") {
+		t.Errorf("failed to override the application name, %v...", f)
+	}
+	c.configFile = filepath.Join("/etc", "myapp", "myapp.json")
+	readdir = func(d string) ([]os.FileInfo, error) {
+		if d != filepath.Join("/etc", "myapp", "myapp.d") {
+			t.Errorf("failed to name the drop-in directory, %s...", d)
+		}
+		return nil, os.ErrNotExist
+	}
+	defer func() { readdir = ioutil.ReadDir }()
+	c.readDropins(context.Background())
+}
+
+func TestExample(t *testing.T) {
+	out := &bytes.Buffer{}
 	c := &Config{}
+	c.Output(out)
+	c.Description("testing examples")
 	c.Example("") // empty input is discarded
 	c.Example("--not-empty")
+	c.Example("--verbose", "print", "everything")
+	if c.Help(); len(c.examples) != 2 || !strings.Contains(out.String(), "\t"+appName+" --not-empty\n\t"+appName+" --verbose\n\t\tprint everything\n") {
+		t.Errorf("failed to print examples with descriptions, %s...", out.String())
+	}
 }
 
 func TestLoad(t *testing.T) {
@@ -154,7 +188,7 @@ func TestLoad(t *testing.T) {
 	var fileStat = &mockStat{modTime: time.Now()}
 	var statError error
 	var createFile *os.File
-	var createError error
+	var createError error = mockError
 	var readfileError error
 	var readfileData []byte
 	var exitCode int = 1
@@ -162,7 +196,7 @@ func TestLoad(t *testing.T) {
 
 	// define overrides
 	stat = func(_ string) (os.FileInfo, error) { return fileStat, statError }
-	create = func(string) (*os.File, error) { return createFile, createError }
+	createTemp = func(string, string) (*os.File, error) { return createFile, createError }
 	readfile = func(string) ([]byte, error) { return readfileData, readfileError }
 	mkdirall = func(string, os.FileMode) error { return nil }
 	exit = func(i int) { exitCode = i }
@@ -330,6 +364,42 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoadContext(t *testing.T) {
+	os.Args = []string{}
+	defer func() { stat, readfile, createTemp = os.Stat, ioutil.ReadFile, ioutil.TempFile }()
+	block, entered, exited := make(chan struct{}), make(chan struct{}, 1), make(chan struct{}, 1)
+	stat = func(_ string) (os.FileInfo, error) { return nil, mockError }
+	readfile = func(string) ([]byte, error) {
+		defer func() { exited <- struct{}{} }()
+		entered <- struct{}{}
+		<-block
+		return nil, mockError
+	}
+	createTemp = func(string, string) (*os.File, error) { return nil, mockError }
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+
+	// test cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if e := c.LoadContext(ctx); !errors.Is(e, context.Canceled) {
+		t.Errorf("failed to respect cancelled context, %v...", e)
+	}
+
+	// test deadline while blocked reading a file
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if e := c.LoadContext(ctx, "/blocking/gonf.json"); !errors.Is(e, context.DeadlineExceeded) {
+		t.Errorf("failed to respect context deadline, %v...", e)
+	}
+
+	// release the abandoned read before the mocks are restored
+	<-entered
+	close(block)
+	<-exited
+}
+
 func TestReload(t *testing.T) {
 	c := &Config{}
 
@@ -375,7 +445,7 @@ func TestReload(t *testing.T) {
 
 	// test expecting success
 	if e := c.Reload(); e != nil {
-		t.Error("failed to successfully parse, %s\n", e)
+		t.Errorf("failed to successfully parse, %s\n", e)
 	}
 }
 
@@ -385,11 +455,24 @@ func TestSave(t *testing.T) {
 		t.Error("failed to acquire temporary directory...")
 	}
 	cf := filepath.Join(d, "gonf.json")
-	defer os.Remove(cf)
+	defer os.RemoveAll(d)
 
 	var createError error
-	var createFile *os.File
-	create = func(string) (*os.File, error) { return createFile, createError }
+	var renameError error
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		if createError != nil {
+			return nil, createError
+		}
+		return ioutil.TempFile(dir, pattern)
+	}
+	rename = func(from, to string) error {
+		if renameError != nil {
+			return renameError
+		}
+		return os.Rename(from, to)
+	}
+	stat = os.Stat
+	defer func() { createTemp, rename = ioutil.TempFile, os.Rename }()
 
 	c := &Config{}
 
@@ -398,22 +481,46 @@ func TestSave(t *testing.T) {
 		t.Error("failed to identify empty configuration file name...")
 	}
 
-	// test bypass create error but nil target (eg. fail json encode?)
+	// test encoder error
 	c.configFile = cf
+	c.Target(make(chan int))
 	if c.Save() == nil {
 		t.Error("failed to capture encoder error...")
 	}
+	c.Target(nil)
 
 	// test create error behavior
 	createError = mockError
 	if c.Save() == nil {
 		t.Error("failed to capture create error...")
 	}
+	createError = nil
+
+	// test rename error removes the temporary file
+	renameError = mockError
+	if c.Save() == nil {
+		t.Error("failed to capture rename error...")
+	}
+	if l, _ := ioutil.ReadDir(d); len(l) != 0 {
+		t.Error("failed to remove temporary file...")
+	}
+	renameError = nil
 
 	// test with (valid) nil target
-	createFile, createError = os.Create(cf)
 	if c.Save() != nil {
-		t.Error("failed to open temporary file for success scenarior...")
+		t.Error("failed to save file for success scenario...")
+	}
+	if data, _ := ioutil.ReadFile(cf); string(data) != "null\n" {
+		t.Errorf("failed to write file contents, %s...", data)
+	}
+
+	// test existing permissions are preserved
+	os.Chmod(cf, 0640)
+	if c.Save() != nil {
+		t.Error("failed to save existing file...")
+	}
+	if fi, _ := os.Stat(cf); fi.Mode().Perm() != 0640 {
+		t.Error("failed to preserve file permissions...")
 	}
 }
 
@@ -451,3 +558,455 @@ func TestConfigFile(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+type mockLogger struct {
+	Secret string
+	Public string
+	infos  int
+	debugs int
+}
+
+func (l *mockLogger) Info(string, ...interface{})  { l.infos++ }
+func (l *mockLogger) Debug(string, ...interface{}) { l.debugs++ }
+
+func TestOnReload(t *testing.T) {
+	var readfileData []byte = []byte(`{"Secret": "new", "Public": "new"}`)
+	stat = func(_ string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return readfileData, nil }
+
+	var changes ChangeSet
+	ml := &mockLogger{Secret: "old", Public: "old"}
+	c := &Config{configFile: "test.gonf.json"}
+	c.Target(ml)
+	c.OnReload(nil)
+	c.OnReload(func(cs ChangeSet) { changes = cs })
+	c.Sensitive("Secret")
+
+	if e := c.Reload(); e != nil || len(changes) != 2 {
+		t.Fatalf("failed to report changes on reload, %v...", e)
+	}
+	if changes[0].Key != "Public" || changes[0].Old != "old" || changes[0].New != "new" {
+		t.Error("failed to report public change...")
+	}
+	if changes[1].Key != "Secret" || changes[1].Old != redacted || changes[1].New != redacted {
+		t.Error("failed to redact sensitive change...")
+	}
+	if ml.infos != 1 || ml.debugs != 2 {
+		t.Error("failed to log changes...")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	readfileData := []byte(`{"OptionString": "file"}`)
+	stat = func(_ string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return readfileData, nil }
+
+	c := &Config{configFile: "test.gonf.json"}
+	c.Target(&mockConfig{})
+	first, second := c.Changes(), c.Changes()
+
+	// test every subscriber receives the change set with its source
+	c.Reload()
+	for _, ch := range []<-chan ChangeSet{first, second} {
+		select {
+		case cs := <-ch:
+			if len(cs) != 1 || cs[0].Key != "OptionString" || cs[0].New != "file" || cs[0].Source != "test.gonf.json" {
+				t.Errorf("failed to receive changes, %+v...", cs)
+			}
+		default:
+			t.Error("failed to send changes to subscriber...")
+		}
+	}
+
+	// test a full buffer drops changes instead of blocking
+	for i := 0; i < cap(first)+1; i++ {
+		c.Apply(map[string]interface{}{"OptionNumber": i + 1})
+	}
+	if len(first) != cap(first) {
+		t.Errorf("failed to drop changes for a full buffer, %d...", len(first))
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c := &Config{}
+	c.Target(&mockConfig{})
+	var direct, nested, unrelated ChangeSet
+	c.Watch("OptionString", func(cs ChangeSet) { direct = cs })
+	c.Watch("ExplicitComposite", func(cs ChangeSet) { nested = cs })
+	c.Watch("OptionBool", func(cs ChangeSet) { unrelated = cs })
+	c.Watch("", func(ChangeSet) { t.Error("failed to ignore empty key...") })
+	c.Watch("OptionString", nil)
+
+	c.Apply(map[string]interface{}{"OptionString": "a", "OptionNumber": 2, "ExplicitComposite": map[string]interface{}{"DepthByOption": 3}})
+	if len(direct) != 1 || direct[0].Key != "OptionString" || len(nested) != 1 || nested[0].Key != "ExplicitComposite.DepthByOption" || unrelated != nil {
+		t.Errorf("failed to notify watchers of their keys, %+v %+v %+v...", direct, nested, unrelated)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Add("OptionString", "", "", "-s:", "--string")
+	c.Add("OptionBool", "", "", "-b")
+	os.Args = []string{"app", "first", "-s", "consumed", "second", "-b", "-", "--", "-b", "--string=forwarded"}
+	c.parseOptions()
+	if a := c.Args(); len(a) != 5 || a[0] != "first" || a[1] != "second" || a[2] != "-" || a[3] != "-b" || a[4] != "--string=forwarded" {
+		t.Errorf("failed to capture remaining arguments, %v...", a)
+	}
+}
+
+func TestRepeatedOptions(t *testing.T) {
+	os.Args = []string{"app", "-H", "a", "--header=b", "-Hc", "-n", "1", "-n2"}
+	os.Clearenv()
+	stat = func(_ string) (os.FileInfo, error) { return nil, mockError }
+	readfile = func(string) ([]byte, error) { return []byte(`{"OptionSlice": ["file"]}`), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("OptionSlice", "", "", "-H:", "--header")
+	c.Add("OptionNumbers", "", "", "-n:")
+	if e := c.Load("/tmp/gonf.json"); e != nil || len(mc.OptionSlice) != 3 || mc.OptionSlice[0] != "a" || mc.OptionSlice[1] != "b" || mc.OptionSlice[2] != "c" {
+		t.Errorf("failed to accumulate repeated options, %v %v...", e, mc.OptionSlice)
+	}
+	if len(mc.OptionNumbers) != 2 || mc.OptionNumbers[0] != 1 || mc.OptionNumbers[1] != 2 {
+		t.Errorf("failed to cast accumulated options, %v...", mc.OptionNumbers)
+	}
+}
+
+func TestShortEquals(t *testing.T) {
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("OptionString", "", "", "-k")
+	c.Add("GetoptGreedy", "", "", "-g:")
+	c.Add("GetoptShort", "", "", "-b")
+	c.Add("GetoptLongBool", "", "", "-l")
+	os.Args = []string{"app", "-k=value", "-g=greedy", "-lb="}
+	if e := c.to(c.parseOptions()); e != nil || mc.OptionString != "value" || mc.GetoptGreedy != "greedy" || !mc.GetoptShort || !mc.GetoptLongBool {
+		t.Errorf("failed to parse short options with equals, %v...", e)
+	}
+}
+
+func TestShortRunes(t *testing.T) {
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("GetoptShort", "", "", "-λ")
+	c.Add("GetoptGreedy", "", "", "-ü:")
+	c.Add("OptionString", "", "", "-k")
+	os.Args = []string{"app", "-λüvalüe", "-k=日本"}
+	if e := c.to(c.parseOptions()); e != nil || !mc.GetoptShort || mc.GetoptGreedy != "valüe" || mc.OptionString != "日本" {
+		t.Errorf("failed to parse multi-byte short options, %v %+v...", e, mc)
+	}
+}
+
+func TestSetOption(t *testing.T) {
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{}, nil }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"OptionString": "file", "ExplicitComposite": {"TripleDepth": "file"}}`), nil
+	}
+	os.Setenv("ENV_STRING", "env")
+	os.Args = []string{"app", "--set", "ExplicitComposite.TripleDepth=set", "--set=OptionString=a=b", "--set", "EnvString=", "--set", "invalid"}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("EnvString", "", "ENV_STRING")
+	if e := c.Load("/tmp/app.json"); e != nil || mc.ExplicitComposite.TripleDepth != "set" || mc.OptionString != "a=b" || mc.EnvString != "" {
+		t.Errorf("failed to override keys with --set, %v %+v...", e, mc)
+	}
+	if s := c.source("ExplicitComposite.TripleDepth"); s != "option --set" {
+		t.Errorf("failed to record --set as the source, %s...", s)
+	}
+
+	// test a registered --set option is left alone
+	os.Args = []string{"app", "--set", "value"}
+	c = &Config{}
+	c.Target(mc)
+	c.Add("OptionString", "", "", "--set")
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "value" {
+		t.Errorf("failed to defer to registered --set option, %v %s...", e, mc.OptionString)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	os.Clearenv()
+	stat = func(_ string) (os.FileInfo, error) { return nil, mockError }
+	readfile = func(string) ([]byte, error) { return []byte(`{"EnvString": "info"}`), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("OptionString", "", "", "--level")
+	c.Add("EnvString", "", "LEVEL")
+	c.Add("OptionSlice", "", "", "--tag")
+
+	// test unregistered name
+	if c.Choices("missing", "a") == nil {
+		t.Error("failed to reject choices for unregistered name...")
+	}
+	c.Choices("OptionString", "debug", "info")
+	c.Choices("EnvString", "debug", "info")
+	c.Choices("OptionSlice", "a", "b")
+
+	// test valid choices
+	os.Args = []string{"app", "--level", "info", "--tag=a", "--tag=b"}
+	os.Setenv("LEVEL", "debug")
+	if e := c.Load("/tmp/gonf.json"); e != nil || mc.OptionString != "info" || mc.EnvString != "debug" || len(mc.OptionSlice) != 2 {
+		t.Errorf("failed to accept valid choices, %v...", e)
+	}
+
+	// test invalid choices are discarded
+	mc.OptionSlice = nil
+	os.Args = []string{"app", "--level", "trace", "--tag=a", "--tag=c"}
+	os.Clearenv()
+	if e := c.Load("/tmp/gonf.json"); !errors.Is(e, errInvalidChoice) || mc.OptionString != "info" || mc.EnvString != "info" || len(mc.OptionSlice) != 0 {
+		t.Errorf("failed to reject invalid choices, %v...", e)
+	}
+
+	// test choices in help output
+	if s := c.setting("OptionString").String(); !strings.Contains(s, "debug, info") {
+		t.Error("failed to render choices...")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+
+	c := &Config{}
+	c.Description("testing groups")
+	c.Add("address", "listen address", "", "--address")
+	c.Add("level", "log level", "", "--level")
+	c.Add("other", "ungrouped", "", "--other")
+
+	if c.Group("Networking", "missing") == nil {
+		t.Error("failed to reject unregistered name...")
+	}
+	if c.Group("", "address") == nil {
+		t.Error("failed to reject empty group...")
+	}
+	c.Group("Networking", "address")
+	c.Group("Logging", "level")
+	c.Group("Networking", "address")
+	c.Help()
+
+	o, n, l := strings.Index(fmtPrintfData, "--other"), strings.Index(fmtPrintfData, "Networking:"), strings.Index(fmtPrintfData, "Logging:")
+	if o < 0 || n < o || l < n || strings.Count(fmtPrintfData, "Networking:") != 1 || strings.Index(fmtPrintfData, "--level") < l {
+		t.Errorf("failed to render groups in help, %s...", fmtPrintfData)
+	}
+}
+
+func TestHidden(t *testing.T) {
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Description("testing hidden")
+	c.Add("OptionString", "", "", "--experimental")
+	if c.Hidden("missing") == nil {
+		t.Error("failed to reject unregistered name...")
+	}
+	c.Hidden("OptionString")
+	c.Help()
+	if strings.Contains(fmtPrintfData, "--experimental") {
+		t.Error("failed to hide setting from help...")
+	}
+
+	os.Args = []string{"app", "--experimental=yes"}
+	if e := c.to(c.parseOptions()); e != nil || mc.OptionString != "yes" {
+		t.Error("failed to parse hidden setting...")
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+	os.Clearenv()
+
+	ml := &mockLogger{}
+	c := &Config{}
+	c.Target(ml)
+	c.Description("testing deprecated")
+	c.Add("Public", "public value", "PUBLIC", "--public")
+	c.Add("Secret", "secret value", "SECRET", "--secret")
+	if c.Deprecated("missing", "", "--old") == nil {
+		t.Error("failed to reject unregistered name...")
+	}
+	if c.Deprecated("Public", "") == nil {
+		t.Error("failed to reject empty deprecation...")
+	}
+	c.Deprecated("Public", "", "--old-public", "-o")
+	c.Deprecated("Secret", "OLD_SECRET")
+	if len(c.settings) != 2 || c.setting("Public").OldOptions[1] != "-o" {
+		t.Errorf("failed to register deprecated aliases on the existing settings, %+v...", c.settings)
+	}
+
+	os.Args = []string{"app", "--old-public=one", "--public=two"}
+	os.Setenv("OLD_SECRET", "three")
+	if e := c.to(c.parseEnvs(), c.parseOptions()); e != nil || ml.Public != "two" || ml.Secret != "three" || ml.infos != 2 {
+		t.Errorf("failed to parse deprecated aliases, %v %d...", e, ml.infos)
+	}
+	os.Args = []string{"app", "-o", "four"}
+	if e := c.to(c.parseOptions()); e != nil || ml.Public != "four" || ml.infos != 3 {
+		t.Error("failed to parse deprecated short alias...")
+	}
+
+	c.Help()
+	if strings.Count(fmtPrintfData, "--old-public") != 1 || !strings.Contains(fmtPrintfData, "deprecated: OLD_SECRET") {
+		t.Errorf("failed to document deprecated aliases, %s...", fmtPrintfData)
+	}
+	os.Clearenv()
+}
+
+func TestVersion(t *testing.T) {
+	var exitCode int = 1
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+	exit = func(i int) { exitCode = i }
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{GoVersion: "go1.0", Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abc123"}, {Key: "GOOS", Value: "linux"}}}, true
+	}
+	defer func() { readBuildInfo = debug.ReadBuildInfo }()
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+
+	// test without version
+	os.Args = []string{"app", "--version"}
+	c.parseOptions()
+	if exitCode != 1 || fmtPrintfData != "" {
+		t.Error("failed to ignore version without a version...")
+	}
+
+	// test with version
+	c.Version("1.2.3")
+	for _, a := range []string{"-V", "--version"} {
+		exitCode, fmtPrintfData = 1, ""
+		os.Args = []string{"app", a}
+		c.parseOptions()
+		if exitCode != 0 || !strings.Contains(fmtPrintfData, "1.2.3") || !strings.Contains(fmtPrintfData, "vcs.revision=abc123") || strings.Contains(fmtPrintfData, "GOOS") {
+			t.Errorf("failed to print version with %s, %s...", a, fmtPrintfData)
+		}
+	}
+}
+
+func TestHelpEnvironment(t *testing.T) {
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+	os.Clearenv()
+	os.Setenv("APP_PATH", "/tmp")
+	os.Setenv("APP_TOKEN", "secret")
+	defer os.Clearenv()
+
+	c := &Config{}
+	c.Description("testing environment help")
+	c.Add("path", "the path", "APP_PATH", "--path")
+	c.Add("token", "the token", "APP_TOKEN")
+	c.Add("level", "the level", "APP_LEVEL")
+	c.Add("internal", "internal", "APP_INTERNAL")
+	c.Add("flag", "a flag", "", "--flag")
+	c.Sensitive("token")
+	c.Hidden("internal")
+	c.Help()
+
+	// test values are omitted by default
+	e := fmtPrintfData[strings.Index(fmtPrintfData, "Environment:"):]
+	if !strings.Contains(e, "APP_PATH") || strings.Contains(e, "/tmp") || strings.Contains(e, "APP_TOKEN=") {
+		t.Errorf("failed to omit environment values by default, %s...", e)
+	}
+
+	// test values are rendered when enabled
+	fmtPrintfData = ""
+	c.HelpValues(true)
+	c.Help()
+	e = fmtPrintfData[strings.Index(fmtPrintfData, "Environment:"):]
+	if !strings.Contains(e, "APP_PATH=/tmp") || !strings.Contains(e, "APP_TOKEN=***") || strings.Contains(e, "secret") ||
+		!strings.Contains(e, "APP_LEVEL") || strings.Contains(e, "APP_INTERNAL") || strings.Contains(e, "--flag") {
+		t.Errorf("failed to render environment section, %s...", e)
+	}
+}
+
+func TestEnvPrefix(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("EnvString", "", "", "--string")
+	c.Add("EnvBool", "", "EXPLICIT_BOOL")
+	c.EnvPrefix("myapp")
+	if c.Add("EnvNumber", "registration without env or options", "") != nil {
+		t.Error("failed to register without env or options using a prefix...")
+	}
+	c.Add("ExplicitComposite.DepthByOption", "", "")
+
+	os.Setenv("MYAPP_ENVSTRING", "string")
+	os.Setenv("MYAPP_ENVNUMBER", "3")
+	os.Setenv("EXPLICIT_BOOL", "true")
+	os.Setenv("MYAPP_EXPLICITCOMPOSITE_DEPTHBYOPTION", "7")
+	if e := c.to(c.parseEnvs()); e != nil || mc.EnvString != "string" || mc.EnvNumber != 3 || !mc.EnvBool || mc.ExplicitComposite.DepthByOption != 7 {
+		t.Errorf("failed to bind prefixed environment variables, %v...", e)
+	}
+
+	// test clearing the prefix
+	c.EnvPrefix("")
+	if s := c.setting("EnvString"); s.Env != "" || s.Prefixed {
+		t.Error("failed to clear prefixed environment variables...")
+	}
+	if s := c.setting("EnvBool"); s.Env != "EXPLICIT_BOOL" {
+		t.Error("failed to preserve explicit environment variables...")
+	}
+}
+
+func TestAutoEnv(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("EnvString", "", "REGISTERED")
+	os.Setenv("ENVBYTAG", "tag")
+	os.Setenv("EXPLICITCOMPOSITE_DEPTHBYOPTION", "7")
+	os.Setenv("TRIPLEDEPTH", "triple")
+	os.Setenv("ENVSTRING", "auto")
+	os.Setenv("REGISTERED", "registered")
+
+	// test disabled by default
+	if e := c.to(c.parseEnvs()); e != nil || mc.EnvByTag != "" {
+		t.Error("failed to ignore unregistered environment variables...")
+	}
+
+	c.AutoEnv()
+	if e := c.to(c.parseEnvs()); e != nil || mc.EnvByTag != "tag" || mc.ExplicitComposite.DepthByOption != 7 || mc.TripleDepth != "triple" || mc.EnvString != "registered" {
+		t.Errorf("failed to bind environment variables for all fields, %v %+v...", e, mc)
+	}
+
+	c.EnvPrefix("app")
+	os.Setenv("APP_OPTIONBOOL", "true")
+	if e := c.to(c.parseEnvs()); e != nil || !mc.OptionBool {
+		t.Errorf("failed to bind prefixed environment variables for all fields, %v...", e)
+	}
+}
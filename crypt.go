@@ -0,0 +1,114 @@
+package gonf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+)
+
+// The header that identifies a configuration file encrypted with AES-256-GCM,
+// which is followed by the nonce and the sealed contents.
+const encryptedHeader = "GONF-AES256GCM\n"
+
+var (
+	errBadKey  = errors.New("encryption key must be 32 bytes...")
+	errNoKey   = errors.New("no key supplied to decrypt the configuration file...")
+	errDecrypt = errors.New("unable to decrypt the configuration file...")
+
+	randReader io.Reader = rand.Reader
+)
+
+func (c *Config) secret() ([]byte, error) {
+	if c.key != nil {
+		return c.key, nil
+	}
+	env := os.Getenv("GONF_KEY")
+	if env == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(env)
+	if err != nil || len(key) != 32 {
+		return nil, errBadKey
+	}
+	return key, nil
+}
+
+func (c *Config) aead(key []byte) (cipher.AEAD, error) {
+	if key == nil {
+		return nil, errNoKey
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errBadKey
+	}
+	return cipher.NewGCM(b)
+}
+
+func (c *Config) sealed(err error) bool {
+	return errors.Is(err, errNoKey) || errors.Is(err, errBadKey) || errors.Is(err, errDecrypt)
+}
+
+func (c *Config) decrypt(data []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedHeader)) {
+		return data, false, nil
+	}
+	key, err := c.secret()
+	if err != nil {
+		return nil, true, err
+	}
+	g, err := c.aead(key)
+	if err != nil {
+		return nil, true, err
+	}
+	data = data[len(encryptedHeader):]
+	if len(data) < g.NonceSize() {
+		return nil, true, errDecrypt
+	}
+	plain, err := g.Open(nil, data[:g.NonceSize()], data[g.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, true, errDecrypt
+	}
+	return plain, true, nil
+}
+
+func (c *Config) encrypt(data []byte) ([]byte, error) {
+	if !c.encrypted && c.key == nil {
+		return data, nil
+	}
+	key, err := c.secret()
+	if err != nil {
+		return nil, err
+	}
+	g, err := c.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, g.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(encryptedHeader), nonce...)
+	return g.Seal(out, nonce, data, []byte(encryptedHeader)), nil
+}
+
+// Supply the 32 byte key used to decrypt configuration files encrypted with
+// AES-256-GCM, and to encrypt the file written by Save.  Without a key, the
+// base64 encoded `GONF_KEY` environment variable is used, and Save only
+// encrypts files that were encrypted when loaded.  A nil key clears it.
+func (c *Config) Key(key []byte) error {
+	if key != nil && len(key) != 32 {
+		return errBadKey
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = nil
+	if key != nil {
+		c.key = append([]byte{}, key...)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncrypted(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	key := bytes.Repeat([]byte("k"), 32)
+	if (&Config{}).Key([]byte("short")) == nil {
+		t.Error("failed to reject a bad key...")
+	}
+
+	// test encrypted save and load with a supplied key
+	c := &Config{}
+	c.Target(&mockConfig{OptionString: "token"})
+	c.Key(key)
+	data, e := c.encode("/tmp/app.json")
+	if e != nil || !strings.HasPrefix(string(data), encryptedHeader) || strings.Contains(string(data), "token") {
+		t.Fatalf("failed to encrypt configuration, %v %q...", e, data)
+	}
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return data, nil }
+	mc := &mockConfig{}
+	c = &Config{}
+	c.Target(mc)
+	c.Key(key)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "token" || !c.encrypted {
+		t.Errorf("failed to load encrypted configuration, %v %+v...", e, mc)
+	}
+
+	// test key from the environment
+	os.Setenv("GONF_KEY", base64.StdEncoding.EncodeToString(key))
+	mc = &mockConfig{}
+	c = &Config{}
+	c.Target(mc)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "token" {
+		t.Errorf("failed to load encrypted configuration using environment key, %v %+v...", e, mc)
+	}
+	if out, _ := c.encode("/tmp/app.json"); !strings.HasPrefix(string(out), encryptedHeader) {
+		t.Error("failed to preserve encryption on save...")
+	}
+
+	// test missing and wrong keys do not overwrite the file
+	createTemp = nil
+	defer func() { createTemp = ioutil.TempFile }()
+	for _, env := range []string{"", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("x"), 32)), "invalid"} {
+		os.Setenv("GONF_KEY", env)
+		c = &Config{}
+		c.Target(&mockConfig{})
+		if e := c.Load("/tmp/app.json"); !c.sealed(e) {
+			t.Errorf("failed to reject encrypted configuration with key %q...", env)
+		}
+	}
+}
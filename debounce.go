@@ -0,0 +1,53 @@
+package gonf
+
+import "time"
+
+// A reload waiting out the Debounce interval, shared by every caller that
+// arrives before it runs.
+type queued struct {
+	done chan struct{}
+	err  error
+}
+
+// Run fn immediately if the last reload started at least one interval ago,
+// otherwise wait out the rest of the interval, letting every call made in the
+// meantime share the result of a single reload.
+func (c *Config) debounced(fn func() error) error {
+	c.mu.Lock()
+	if c.debounce <= 0 {
+		c.mu.Unlock()
+		return fn()
+	} else if p := c.queued; p != nil {
+		c.mu.Unlock()
+		<-p.done
+		return p.err
+	}
+	p := &queued{done: make(chan struct{})}
+	wait := c.debounce - time.Since(c.reloaded)
+	if wait > 0 {
+		c.queued = p
+	} else {
+		c.reloaded = time.Now()
+	}
+	c.mu.Unlock()
+	if wait > 0 {
+		sleep(wait)
+		c.mu.Lock()
+		c.queued, c.reloaded = nil, time.Now()
+		c.mu.Unlock()
+	}
+	p.err = fn()
+	close(p.done)
+	return p.err
+}
+
+// Coalesce bursts of Reload calls, such as from repeated signals, file events,
+// or a ReloadHandler, into at most one reload per interval, protecting
+// applications whose OnReload callbacks are expensive.  A call made within the
+// interval of the last reload waits for the rest of it, and shares the result
+// with every other call made meanwhile.  Zero disables it.
+func (c *Config) Debounce(interval time.Duration) {
+	c.mu.Lock()
+	c.debounce = interval
+	c.mu.Unlock()
+}
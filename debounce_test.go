@@ -0,0 +1,70 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile, sleep = os.Stat, ioutil.ReadFile, time.Sleep }()
+	data, modTime := `{"OptionNumber": 1}`, time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+	var sleeps int32
+	slept, release := make(chan time.Duration, 3), make(chan struct{})
+	sleep = func(d time.Duration) {
+		atomic.AddInt32(&sleeps, 1)
+		slept <- d
+		<-release
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	reloads := 0
+	c.OnReload(func(ChangeSet) { reloads++ })
+	c.Load("/tmp/app.json")
+	c.Debounce(time.Minute)
+
+	// test the first reload runs immediately
+	data, modTime = `{"OptionNumber": 2}`, modTime.Add(time.Second)
+	if e := c.Reload(); e != nil || reloads != 1 || mc.OptionNumber != 2 {
+		t.Errorf("failed to reload immediately, %v %d %v...", e, reloads, mc.OptionNumber)
+	}
+
+	// test a burst within the interval shares a single reload
+	data, modTime = `{"OptionNumber": 3}`, modTime.Add(time.Second)
+	errs := make(chan error, 3)
+	go func() { errs <- c.Reload() }()
+	if d := <-slept; d <= 0 || d > time.Minute {
+		t.Errorf("failed to wait out the interval, %v...", d)
+	}
+	go func() { errs <- c.Reload() }()
+	go func() { errs <- c.Reload() }()
+	select {
+	case d := <-slept:
+		t.Errorf("failed to share the pending reload, waited %v again...", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	for i := 0; i < 3; i++ {
+		if e := <-errs; e != nil {
+			t.Errorf("failed to share reload result, %v...", e)
+		}
+	}
+	if n := atomic.LoadInt32(&sleeps); n != 1 || reloads != 2 || mc.OptionNumber != 3 {
+		t.Errorf("failed to coalesce reloads, %d %d %v...", n, reloads, mc.OptionNumber)
+	}
+
+	// test zero disables it
+	c.Debounce(0)
+	data, modTime = `{"OptionNumber": 4}`, modTime.Add(time.Second)
+	if e := c.Reload(); e != nil || reloads != 3 || mc.OptionNumber != 4 {
+		t.Errorf("failed to disable debounce, %v %d %v...", e, reloads, mc.OptionNumber)
+	}
+}
@@ -0,0 +1,56 @@
+package gonf
+
+import "errors"
+
+var errNilDerive = errors.New("a derived default requires a name and a function...")
+
+// A default computed from the merged configuration by a registered function.
+type derived struct {
+	name string
+	fn   func(map[string]interface{}) interface{}
+}
+
+// Compute the derived defaults for names that were not supplied by any layer
+// or by another source, in the order they were registered, so that each may
+// depend on those before it.
+func (c *Config) derive(merged map[string]interface{}, supplied ...map[string]interface{}) map[string]interface{} {
+	c.mu.RLock()
+	list := append([]derived{}, c.derived...)
+	c.mu.RUnlock()
+	out := map[string]interface{}{}
+	if len(list) == 0 {
+		return out
+	}
+	merged = c.clone(merged)
+	for _, d := range list {
+		found := c.source(d.name) != "default"
+		for _, m := range supplied {
+			if v, ok := c.lookup(m, d.name); ok && v != nil {
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		if v := d.fn(c.clone(merged)); v != nil {
+			c.set(out, d.name, v)
+			c.set(merged, d.name, v)
+		}
+	}
+	return out
+}
+
+// Register a function that computes the default of a dot-notation name from
+// the merged configuration, such as an advertised address from the bound
+// address, whenever no file, environment variable, or option supplies it.
+// Derived defaults are computed after merging and before validation, and a
+// nil result leaves the default unchanged.
+func (c *Config) Derive(name string, fn func(map[string]interface{}) interface{}) error {
+	if name == "" || fn == nil {
+		return errNilDerive
+	}
+	c.mu.Lock()
+	c.derived = append(c.derived, derived{name, fn})
+	c.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,49 @@
+package gonf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDerive(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	file := `{"EnvString": "10.0.0.1"}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(file), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Derive("", nil); e != errNilDerive {
+		t.Errorf("failed to reject an empty derived default, %v...", e)
+	}
+	c.Derive("OptionString", func(m map[string]interface{}) interface{} { return fmt.Sprintf("http://%v/", m["EnvString"]) })
+	c.Derive("EnvBool", func(map[string]interface{}) interface{} { return nil })
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "http://10.0.0.1/" || mc.EnvBool {
+		t.Errorf("failed to derive default, %v %s...", e, mc.OptionString)
+	}
+
+	// test derived defaults follow a reload
+	file = `{"EnvString": "10.0.0.2"}`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	if e := c.Reload(); e != nil || mc.OptionString != "http://10.0.0.2/" {
+		t.Errorf("failed to derive default on reload, %v %s...", e, mc.OptionString)
+	}
+
+	// test supplied values are not replaced
+	os.Args = []string{"--OptionString=supplied"}
+	c = &Config{}
+	c.Target(mc)
+	c.Add("OptionString", "", "", "--OptionString")
+	c.Derive("OptionString", func(map[string]interface{}) interface{} { return "derived" })
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "supplied" {
+		t.Errorf("failed to keep supplied value, %v %s...", e, mc.OptionString)
+	}
+}
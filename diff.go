@@ -0,0 +1,150 @@
+package gonf
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const redacted = "***"
+
+type logger interface {
+	Info(string, ...interface{})
+	Debug(string, ...interface{})
+}
+
+// A single key that was modified by a reload, where the key uses the same
+// dot-notation as Add and sensitive values are redacted.  The source is the
+// file, environment variable, or command line option that now sets it.
+type Change struct {
+	Key    string      `json:"key"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+	Source string      `json:"source"`
+}
+
+// The complete set of changes applied by a single reload.
+type ChangeSet []Change
+
+// A callback for the changes to a single key and the keys beneath it.
+type watcher struct {
+	key string
+	fn  func(ChangeSet)
+}
+
+func (c *Config) flatten(prefix string, in map[string]interface{}, out map[string]interface{}) map[string]interface{} {
+	for k, v := range in {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			c.flatten(k, m, out)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (c *Config) snapshot() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.flatten("", c.document(), map[string]interface{}{})
+}
+
+func (c *Config) diff(before, after map[string]interface{}) ChangeSet {
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	var changes ChangeSet
+	for k := range keys {
+		if reflect.DeepEqual(before[k], after[k]) {
+			continue
+		}
+		ch := Change{Key: k, Old: before[k], New: after[k], Source: c.source(k)}
+		if c.isSensitive(k) {
+			ch.Old, ch.New = redacted, redacted
+		}
+		changes = append(changes, ch)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func (c *Config) logger() logger {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if l, ok := c.target.(logger); ok {
+		return l
+	}
+	return nil
+}
+
+func (c *Config) changed(changes ChangeSet) {
+	if c.event(slog.LevelInfo, "configuration reloaded", slog.String("file", c.ConfigFile()), slog.Int("changes", len(changes))) {
+		for _, ch := range changes {
+			c.event(slog.LevelDebug, "configuration changed", slog.String("key", ch.Key), slog.Any("old", ch.Old), slog.Any("new", ch.New))
+		}
+	} else if l := c.logger(); l != nil {
+		l.Info("configuration reloaded with %d changes", len(changes))
+		for _, ch := range changes {
+			l.Debug("%s changed from %v to %v", ch.Key, ch.Old, ch.New)
+		}
+	}
+	c.refresh()
+	c.toggled(true)
+	c.hook(changes)
+	c.mu.RLock()
+	callbacks, subscribers, watchers := c.onReload, c.subscribers, c.watchers
+	c.mu.RUnlock()
+	for _, fn := range callbacks {
+		fn(changes)
+	}
+	for _, w := range watchers {
+		var matched ChangeSet
+		for _, ch := range changes {
+			if ch.Key == w.key || strings.HasPrefix(ch.Key, w.key+".") {
+				matched = append(matched, ch)
+			}
+		}
+		if len(matched) > 0 {
+			w.fn(matched)
+		}
+	}
+	for _, ch := range subscribers {
+		select {
+		case ch <- changes:
+		default:
+			c.notify(slog.LevelWarn, "dropped configuration changes for a subscriber that is not receiving")
+		}
+	}
+}
+
+// Register a callback to receive only the changes to a dot-notation key, or
+// to any key beneath it, after each successful Reload or Apply that modifies
+// them, so that only the interested component is notified.
+func (c *Config) Watch(key string, fn func(ChangeSet)) {
+	if key == "" || fn == nil {
+		return
+	}
+	c.mu.Lock()
+	c.watchers = append(c.watchers, watcher{key, fn})
+	c.mu.Unlock()
+}
+
+// Return a channel that receives the set of changed keys after each
+// successful Reload or Apply, so that several subsystems may subscribe
+// independently.  Each call returns a new buffered channel, and change sets
+// are dropped with a warning when its buffer is full.
+func (c *Config) Changes() <-chan ChangeSet {
+	ch := make(chan ChangeSet, 16)
+	c.mu.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.mu.Unlock()
+	return ch
+}
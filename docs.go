@@ -0,0 +1,135 @@
+package gonf
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+func (c *Config) sections() ([]string, map[string][]setting) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	groups := append([]string{""}, c.groups...)
+	sections := map[string][]setting{}
+	for _, s := range c.settings {
+		if !s.Hidden {
+			sections[s.Group] = append(sections[s.Group], s)
+		}
+	}
+	return groups, sections
+}
+
+func (c *Config) roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// Generate a man page in roff format from the description, registered
+// settings, environment variables, examples, and application metadata, so
+// that it can be produced at build time instead of maintained by hand.  Hidden
+// settings are omitted.
+func (c *Config) Man() string {
+	c.mu.RLock()
+	description, examples := c.description, append([]example{}, c.examples...)
+	version, authors, homepage, license := c.version, strings.Join(c.authors, ", "), c.homepage, c.license
+	c.mu.RUnlock()
+	groups, sections := c.sections()
+	name := c.application()
+
+	out := ".TH " + strings.ToUpper(c.roff(name)) + " 1"
+	if version != "" {
+		out += ` "" "` + c.roff(name+" "+version) + `"`
+	}
+	out += "\n"
+	out += ".SH NAME\n" + c.roff(name)
+	if description != "" {
+		out += ` \- ` + c.roff(description)
+	}
+	out += "\n.SH SYNOPSIS\n.B " + c.roff(name) + "\n[\\fIOPTIONS\\fR]\n"
+	if description != "" {
+		out += ".SH DESCRIPTION\n" + c.roff(description) + "\n"
+	}
+	out += ".SH OPTIONS\n.TP\n\\fB\\-h\\fR, \\fB\\-\\-help\\fR\ndisplay help information\n"
+	var env string
+	for _, g := range groups {
+		if g != "" && len(sections[g]) > 0 {
+			out += ".SS " + c.roff(g) + "\n"
+		}
+		for _, s := range sections[g] {
+			if s.Env != "" {
+				env += ".TP\n.B " + c.roff(s.Env) + "\n" + c.roff(s.Description) + "\n"
+			}
+			if len(s.Options) == 0 {
+				continue
+			}
+			var flags []string
+			for _, o := range s.Options {
+				flags = append(flags, "\\fB"+c.roff(strings.TrimSuffix(o, ":"))+"\\fR")
+			}
+			out += ".TP\n" + strings.Join(flags, ", ") + "\n" + c.roff(s.Description) + "\n"
+			if len(s.Choices) > 0 {
+				out += "One of: " + c.roff(strings.Join(s.Choices, ", ")) + "\n"
+			}
+		}
+	}
+	if env != "" {
+		out += ".SH ENVIRONMENT\n" + env
+	}
+	if len(examples) > 0 {
+		out += ".SH EXAMPLES\n"
+		for _, e := range examples {
+			if e.Description == "" {
+				out += ".PP\n" + c.roff(name+" "+e.Usage) + "\n"
+			} else {
+				out += ".TP\n" + c.roff(name+" "+e.Usage) + "\n" + c.roff(e.Description) + "\n"
+			}
+		}
+	}
+	if authors != "" {
+		out += ".SH AUTHORS\n" + c.roff(authors) + "\n"
+	}
+	if license != "" {
+		out += ".SH COPYRIGHT\n" + c.roff(license) + "\n"
+	}
+	if homepage != "" {
+		out += ".SH SEE ALSO\n" + c.roff(homepage) + "\n"
+	}
+	return out
+}
+
+// Generate a Markdown table of the registered settings, including command line
+// options, environment variables, the current value of the target as the
+// default, and the description, to keep user documentation in sync with the
+// code.  Hidden settings are omitted.
+func (c *Config) Markdown() string {
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	defaults := c.snapshot()
+	groups, sections := c.sections()
+	out := "| Name | Options | Environment | Default | Description |\n| --- | --- | --- | --- | --- |\n"
+	for _, g := range groups {
+		for _, s := range sections[g] {
+			var flags []string
+			for _, o := range s.Options {
+				flags = append(flags, "`"+strings.TrimSuffix(o, ":")+"`")
+			}
+			env, def := "", ""
+			if s.Env != "" {
+				env = "`" + s.Env + "`"
+			}
+			if c.isSensitive(s.Name) {
+				def = "`" + redacted + "`"
+			} else if v, ok := defaults[s.Name]; ok {
+				d, _ := json.Marshal(v)
+				def = "`" + string(d) + "`"
+			}
+			desc := s.Description
+			if len(s.Choices) > 0 {
+				desc += " (" + strings.Join(s.Choices, ", ") + ")"
+			}
+			out += "| " + cell.Replace(s.Name) + " | " + strings.Join(flags, ", ") + " | " + env + " | " + cell.Replace(def) + " | " + cell.Replace(desc) + " |\n"
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package gonf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMan(t *testing.T) {
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Description("a test-application")
+	c.Example("--path=/tmp")
+	c.Example("--level=info", "quieter logging")
+	c.Add("OptionString", "the path", "APP_PATH", "-p:", "--path")
+	c.Add("OptionBool", ".leading dot", "APP_BOOL")
+	c.Add("GetoptSkip", "internal", "APP_INTERNAL", "--internal")
+	c.Add("EnvString", "log level", "", "--level")
+	c.Choices("EnvString", "debug", "info")
+	c.Group("Logging", "EnvString")
+	c.Hidden("GetoptSkip")
+
+	m := c.Man()
+	for _, s := range []string{
+		".TH " + strings.ToUpper(c.roff(appName)) + " 1\n",
+		`.SH NAME` + "\n" + c.roff(appName) + ` \- a test\-application`,
+		".TP\n\\fB\\-p\\fR, \\fB\\-\\-path\\fR\nthe path\n",
+		".SS Logging\n.TP\n\\fB\\-\\-level\\fR\nlog level\nOne of: debug, info\n",
+		".SH ENVIRONMENT\n.TP\n.B APP_PATH\nthe path\n.TP\n.B APP_BOOL\n\\&.leading dot\n",
+		".SH EXAMPLES\n.PP\n" + c.roff(appName) + " \\-\\-path=/tmp\n.TP\n" + c.roff(appName) + " \\-\\-level=info\nquieter logging\n",
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("failed to generate man page with %q, %s...", s, m)
+		}
+	}
+	if strings.Contains(m, "internal") {
+		t.Error("failed to omit hidden settings from man page...")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	c := &Config{}
+	c.Target(&mockConfig{OptionString: "a|b", OptionNumber: 1.5})
+	c.Add("OptionString", "the path", "APP_PATH", "-p:", "--path")
+	c.Add("OptionNumber", "a number", "", "--number")
+	c.Add("EnvString", "log level", "LEVEL")
+	c.Add("GetoptSkip", "internal", "APP_INTERNAL")
+	c.Choices("EnvString", "debug", "info")
+	c.Hidden("GetoptSkip")
+
+	m := c.Markdown()
+	for _, s := range []string{
+		"| Name | Options | Environment | Default | Description |\n| --- | --- | --- | --- | --- |\n",
+		"| OptionString | `-p`, `--path` | `APP_PATH` | `\"a\\|b\"` | the path |\n",
+		"| OptionNumber | `--number` |  | `1.5` | a number |\n",
+		"| EnvString |  | `LEVEL` | `\"\"` | log level (debug, info) |\n",
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("failed to generate markdown with %q, %s...", s, m)
+		}
+	}
+	if strings.Contains(m, "internal") {
+		t.Error("failed to omit hidden settings from markdown...")
+	}
+}
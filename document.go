@@ -0,0 +1,30 @@
+package gonf
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Merge configuration documents the way Load merges its sources, where later
+// maps take precedence and nested maps are merged recursively.  The inputs are
+// not modified, but the result may share nested maps with them.
+func Merge(maps ...map[string]interface{}) map[string]interface{} {
+	return (&Config{}).merge(maps...)
+}
+
+// Flatten a configuration document into dot-notation keys (eg. `parent.child`),
+// the same keys used by Add, Get, and each Change.
+func Flatten(m map[string]interface{}) map[string]interface{} {
+	return (&Config{}).flatten("", m, map[string]interface{}{})
+}
+
+// Convert a value from a configuration document into the value pointed to by
+// dst, the way Load applies it to the target, which follows json unmarshal
+// and also converts strings from the command line or environment.
+func Cast(v interface{}, dst interface{}) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() {
+		return errNilTarget
+	}
+	return errors.Join((&Config{}).populate(d.Elem(), v, "")...)
+}
@@ -0,0 +1,43 @@
+package gonf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	base := map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 80.0}, "debug": false}
+	override := map[string]interface{}{"server": map[string]interface{}{"port": 8080.0}, "empty": map[string]interface{}{}}
+
+	// test merging preserves the inputs
+	m := Merge(base, override)
+	if s := m["server"].(map[string]interface{}); s["host"] != "localhost" || s["port"] != 8080.0 || base["server"].(map[string]interface{})["port"] != 80.0 {
+		t.Errorf("failed to merge documents, %v %v...", m, base)
+	}
+
+	// test flattening
+	f := Flatten(m)
+	if len(f) != 4 || f["server.host"] != "localhost" || f["server.port"] != 8080.0 || f["debug"] != false {
+		t.Errorf("failed to flatten document, %v...", f)
+	}
+
+	// test casting strings and numbers
+	var port uint16
+	var ratio float32
+	var hosts []string
+	if e := Cast("8080", &port); e != nil || port != 8080 {
+		t.Errorf("failed to cast string, %v %d...", e, port)
+	}
+	if e := Cast(0.5, &ratio); e != nil || ratio != 0.5 {
+		t.Errorf("failed to cast number, %v %f...", e, ratio)
+	}
+	if e := Cast([]interface{}{"a", "b"}, &hosts); e != nil || len(hosts) != 2 {
+		t.Errorf("failed to cast list, %v %v...", e, hosts)
+	}
+	if e := Cast(70000.0, &port); !errors.Is(e, ErrCast) {
+		t.Errorf("failed to report overflow, %v...", e)
+	}
+	if e := Cast("x", port); e != errNilTarget {
+		t.Errorf("failed to reject non-pointer, %v...", e)
+	}
+}
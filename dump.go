@@ -0,0 +1,91 @@
+package gonf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The layers that supply values, in order of precedence, each mapping the
+// keys they set to the file, environment variable, or option responsible.
+var layers = []string{"api", "option", "env", "dropin", "file", "system"}
+
+type entry struct {
+	Key    string      `json:"-"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+func (c *Config) origins(source string, m map[string]interface{}, out map[string]string) map[string]string {
+	for k := range c.flatten("", m, map[string]interface{}{}) {
+		out[k] = source
+	}
+	return out
+}
+
+func (c *Config) provenance(layer string, origins map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sources == nil {
+		c.sources = map[string]map[string]string{}
+	}
+	c.sources[layer] = c.renameOrigins(c.keyMapper, origins)
+}
+
+func (c *Config) source(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range layers {
+		for k := key; ; k = k[:strings.LastIndex(k, ".")] {
+			if s, ok := c.sources[l][k]; ok {
+				return s
+			} else if !strings.Contains(k, ".") {
+				break
+			}
+		}
+	}
+	return "default"
+}
+
+func (c *Config) entries() []entry {
+	values := c.snapshot()
+	list := make([]entry, 0, len(values))
+	for k, v := range values {
+		if c.isSensitive(k) {
+			v = redacted
+		}
+		list = append(list, entry{Key: k, Value: v, Source: c.source(k)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// Write the effective configuration to w, annotating each key with the file,
+// environment variable, or command line option that set it, or "default".
+// The format is either "text" or "json", and sensitive values are masked.
+// The built-in `--dump-config` command line option writes the text format to
+// standard output during Load, and then terminates the application.
+func (c *Config) Dump(w io.Writer, format string) error {
+	list := c.entries()
+	switch format {
+	case "", "text":
+		for _, e := range list {
+			v, _ := json.Marshal(e.Value)
+			if _, err := fmt.Fprintf(w, "%s = %s\t# %s\n", e.Key, v, e.Source); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		m := map[string]entry{}
+		for _, e := range list {
+			m[e.Key] = e
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(m)
+	}
+	return errUnknownFormat
+}
@@ -0,0 +1,70 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	var exitCode int = -1
+	exit = func(i int) { exitCode = i }
+	name := filepath.Join("/etc", appName, appName+".json")
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(n string) ([]byte, error) {
+		if n == name {
+			return []byte(`{"OptionString": "file", "EnvString": "file", "EnvNumber": 3, "ExplicitComposite": {"DepthByOption": 2}}`), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	os.Setenv("APP_ENV", "env")
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Sensitive("EnvNumber")
+	c.Add("OptionString", "", "", "--string", "-s")
+	c.Add("EnvString", "", "APP_ENV")
+	os.Args = []string{"app", "-s", "option"}
+	c.Load(name)
+
+	out := &bytes.Buffer{}
+	if e := c.Dump(out, "text"); e != nil {
+		t.Fatalf("failed to dump configuration, %v...", e)
+	}
+	for _, l := range []string{
+		`OptionString = "option"	# option -s`,
+		`EnvString = "env"	# env APP_ENV`,
+		`EnvNumber = "***"	# ` + name,
+		`ExplicitComposite.DepthByOption = 2	# ` + name,
+		`OptionBool = false	# default`,
+	} {
+		if !strings.Contains(out.String(), l+"\n") {
+			t.Errorf("failed to dump %s, %s...", l, out)
+		}
+	}
+
+	// test json format
+	out.Reset()
+	var m map[string]map[string]interface{}
+	if e := c.Dump(out, "json"); e != nil || json.Unmarshal(out.Bytes(), &m) != nil || m["EnvString"]["source"] != "env APP_ENV" || m["EnvString"]["value"] != "env" {
+		t.Errorf("failed to dump json, %v %s...", e, out)
+	}
+	if c.Dump(out, "yaml") == nil {
+		t.Error("failed to reject unsupported format...")
+	}
+
+	// test built-in option
+	out.Reset()
+	stdout = out
+	defer func() { stdout = os.Stdout }()
+	os.Args = []string{"app", "--dump-config"}
+	if c.Load(name); exitCode != 0 || !strings.Contains(out.String(), `OptionString = "file"	# `+name) {
+		t.Errorf("failed to dump configuration from the command line, %d %s...", exitCode, out)
+	}
+}
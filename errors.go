@@ -0,0 +1,75 @@
+package gonf
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// No configuration file could be read, or created from the defaults.
+	ErrNoConfigFile = errors.New("no configuration file available...")
+
+	// A configuration file could not be parsed.
+	ErrParse = errors.New("unable to parse configuration file...")
+
+	// A configuration file contains a key that does not match the target.
+	ErrUnknownKey = errors.New("unknown configuration key...")
+
+	// A value could not be converted to the type of the target.
+	ErrCast = errors.New("unable to convert value...")
+
+	// A required setting was not supplied by any source.
+	ErrRequired = errors.New("required setting not supplied...")
+
+	// A configuration file does not match the checksum beside it.
+	ErrChecksum = errors.New("configuration file does not match its checksum...")
+
+	// A configuration file does not match the schema.
+	ErrSchema = errors.New("configuration does not match the schema...")
+
+	// Help, version, or a check was requested, and the ErrorHandling is
+	// ContinueOnError or PanicOnError.
+	ErrHelp = errors.New("help requested...")
+
+	// The configuration was changed after Freeze.
+	ErrFrozen = errors.New("configuration is frozen...")
+
+	// A value is not one of the choices registered for it.
+	ErrInvalidChoice = errInvalidChoice
+)
+
+// Collects every problem encountered by a single Load, so that callers may
+// branch on each cause with errors.Is and errors.As.
+type LoadError struct {
+	Errors []error
+}
+
+func (e *LoadError) Error() string {
+	var messages []string
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *LoadError) Unwrap() []error {
+	return e.Errors
+}
+
+func (c *Config) flattenErrors(errs []error, out []error) []error {
+	for _, err := range errs {
+		if j, ok := err.(interface{ Unwrap() []error }); ok {
+			out = c.flattenErrors(j.Unwrap(), out)
+		} else if err != nil {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+func (c *Config) collect(errs ...error) error {
+	if list := c.flattenErrors(errs, nil); len(list) > 0 {
+		return &LoadError{Errors: list}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package gonf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadError(t *testing.T) {
+	os.Args = []string{"app", "--string", "c"}
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("APP_NUMBER", "abc")
+	data := `{"Unknown": 1}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(n string) ([]byte, error) {
+		if n == "/tmp/app.json" {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Add("OptionString", "", "", "--string")
+	c.Add("EnvNumber", "", "APP_NUMBER")
+	c.Choices("OptionString", "a", "b")
+	c.Strict(true)
+	e := c.Load("/tmp/app.json")
+	var le *LoadError
+	if !errors.As(e, &le) || len(le.Errors) != 3 || !errors.Is(e, ErrUnknownKey) || !errors.Is(e, ErrCast) || !errors.Is(e, ErrInvalidChoice) || errors.Is(e, ErrParse) {
+		t.Errorf("failed to collect load errors, %v...", e)
+	}
+
+	// test parse errors do not replace the file with defaults
+	os.Args = []string{}
+	os.Clearenv()
+	data = `not json`
+	createTemp = nil
+	defer func() { createTemp = ioutil.TempFile }()
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, ErrParse) {
+		t.Errorf("failed to report parse error, %v...", e)
+	}
+
+	// test missing override
+	os.Setenv("GONF_CONFIG", "/tmp/missing.json")
+	if e := c.Load(); !errors.Is(e, ErrNoConfigFile) {
+		t.Errorf("failed to report missing configuration file, %v...", e)
+	}
+	if c.collect(nil, errors.Join(nil)) != nil {
+		t.Error("failed to ignore empty errors...")
+	}
+}
@@ -30,7 +30,7 @@ func (s *Signal) sighup(c *gonf.Config) {
 	if runtime.GOOS == "windows" {
 		return
 	}
-	h := make(chan os.Signal)
+	h := make(chan os.Signal, 1)
 	signal.Notify(h, syscall.SIGHUP)
 	for _ = range h {
 		if c.Reload() == nil {
@@ -0,0 +1,60 @@
+package gonf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"sort"
+)
+
+var run = func(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+// Return the effective configuration as environment variables, named the way
+// EnvPrefix names them, with strings left as is and other values in json.
+func (c *Config) environ() []string {
+	values := c.snapshot()
+	var env []string
+	for k, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+		env = append(env, c.envName(k)+"="+s)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// Run a command once Load has finished, with the effective configuration
+// injected into its environment as variables named the way EnvPrefix names
+// them, and written to a temporary json file whose path is supplied as
+// `GONF_CONFIG`, so that gonf may be used to build launchers and wrappers
+// like `env` or `chpst`.  Standard input and output are inherited, the file
+// is removed once the command exits, and the error of the command, such as
+// an *exec.ExitError, is returned.
+func (c *Config) Exec(ctx context.Context, name string, args ...string) error {
+	f, err := createTemp("", "."+c.application()+".*.json")
+	if err != nil {
+		return err
+	}
+	defer remove(f.Name())
+	c.mu.RLock()
+	data := c.marshal()
+	c.mu.RUnlock()
+	if _, err = f.Write(data); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(append(os.Environ(), c.environ()...), "GONF_CONFIG="+f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return run(cmd)
+}
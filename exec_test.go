@@ -0,0 +1,56 @@
+package gonf
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	defer func() { run = func(cmd *exec.Cmd) error { return cmd.Run() } }()
+	createTemp, remove, readfile = ioutil.TempFile, os.Remove, ioutil.ReadFile
+	var env []string
+	var file string
+	run = func(cmd *exec.Cmd) error {
+		env = cmd.Env
+		for _, e := range env {
+			if strings.HasPrefix(e, "GONF_CONFIG=") {
+				data, _ := readfile(strings.TrimPrefix(e, "GONF_CONFIG="))
+				file = string(data)
+			}
+		}
+		return mockError
+	}
+
+	c := &Config{}
+	c.Target(&mockConfig{OptionString: "value", OptionNumber: 3})
+	c.EnvPrefix("app")
+	if e := c.Exec(context.Background(), "child", "--flag"); e != mockError {
+		t.Errorf("failed to return the error of the command, %v...", e)
+	}
+	joined := strings.Join(env, "\n")
+	if !strings.Contains(joined, "APP_OPTIONSTRING=value\n") || !strings.Contains(joined, "APP_OPTIONNUMBER=3\n") || !strings.Contains(file, `"OptionString":"value"`) {
+		t.Errorf("failed to inject configuration, %s %s...", joined, file)
+	}
+
+	// test the temporary file is removed
+	for _, e := range env {
+		if name := strings.TrimPrefix(e, "GONF_CONFIG="); name != e {
+			if _, err := os.Stat(name); !os.IsNotExist(err) {
+				t.Errorf("failed to remove temporary file, %v...", err)
+			}
+		}
+	}
+
+	// test temporary file errors
+	createTemp = func(string, string) (*os.File, error) { return nil, mockError }
+	defer func() { createTemp = ioutil.TempFile }()
+	if e := c.Exec(context.Background(), "child"); e != mockError {
+		t.Errorf("failed to report temporary file error, %v...", e)
+	}
+}
@@ -0,0 +1,70 @@
+package gonf
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var invalidLabel = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Implemented by an adapter for the metrics registry of the application, such
+// as a prometheus GaugeVec and CounterVec.  Info replaces the single series of
+// an info gauge with a value of one, and Inc increments a counter.
+type Registry interface {
+	Info(name string, labels map[string]string)
+	Inc(name string, labels map[string]string)
+}
+
+// Metrics that export configuration values as labels on an info gauge, and
+// count reloads by result, before notifying any Metrics it replaced.
+type exporter struct {
+	c    *Config
+	r    Registry
+	keys []string
+	next Metrics
+}
+
+func (e *exporter) label(name string) string {
+	name = invalidLabel.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func (e *exporter) Loaded(reload bool, fingerprint string, err error) {
+	prefix := e.label(e.c.application())
+	labels := map[string]string{"fingerprint": fingerprint}
+	for _, k := range e.keys {
+		if e.c.isSensitive(k) {
+			continue
+		} else if v, ok := e.c.Get(k); ok && v != nil {
+			labels[e.label(k)] = fmt.Sprint(v)
+		}
+	}
+	e.r.Info(prefix+"_config_info", labels)
+	if reload {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		e.r.Inc(prefix+"_config_reloads_total", map[string]string{"result": result})
+	}
+	if e.next != nil {
+		e.next.Loaded(reload, fingerprint, err)
+	}
+}
+
+// Export the named dot-notation keys as labels on a `<name>_config_info` gauge,
+// alongside the fingerprint, and count reloads in `<name>_config_reloads_total`
+// by a result of success or failure, through the registry supplied after every
+// Load and Reload.  Dots in keys become underscores in labels, and sensitive
+// keys are never exported.  Any Metrics supplied earlier are still notified.
+func (c *Config) Export(r Registry, keys ...string) {
+	if r == nil {
+		return
+	}
+	c.mu.Lock()
+	c.metrics = &exporter{c: c, r: r, keys: append([]string{}, keys...), next: c.metrics}
+	c.mu.Unlock()
+}
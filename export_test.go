@@ -0,0 +1,59 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockRegistry struct {
+	info     map[string]map[string]string
+	counters map[string]int
+}
+
+func (m *mockRegistry) Info(name string, labels map[string]string) {
+	m.info[name] = labels
+}
+
+func (m *mockRegistry) Inc(name string, labels map[string]string) {
+	m.counters[name+"/"+labels["result"]]++
+}
+
+func TestExport(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	data := `{"OptionString": "first", "EnvString": "secret", "log": {"level": "debug"}}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	mr, mm := &mockRegistry{info: map[string]map[string]string{}, counters: map[string]int{}}, &mockMetrics{}
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Name("2-app")
+	c.Sensitive("EnvString")
+	c.Metrics(mm)
+	c.Export(nil)
+	c.Export(mr, "OptionString", "EnvString", "log.level", "missing")
+	c.Load("/tmp/app.json")
+	info := mr.info["_2_app_config_info"]
+	if len(info) != 3 || info["OptionString"] != "first" || info["log_level"] != "debug" || info["fingerprint"] == "" || mm.fingerprint != info["fingerprint"] {
+		t.Errorf("failed to export info gauge, %v %+v...", mr.info, mm)
+	}
+
+	// test reloads are counted by result
+	data = `{"OptionString": "second"}`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	c.Reload()
+	data = `{`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	c.Reload()
+	if mr.counters["_2_app_config_reloads_total/success"] != 1 || mr.counters["_2_app_config_reloads_total/failure"] != 1 || mr.info["_2_app_config_info"]["OptionString"] != "second" {
+		t.Errorf("failed to count reloads, %v %v...", mr.counters, mr.info)
+	}
+}
@@ -0,0 +1,97 @@
+package gonf
+
+import "strconv"
+
+// The subtree of the merged configuration that holds feature flags.
+const featureKey = "flags"
+
+// A callback for a single feature flag.
+type feature struct {
+	name string
+	fn   func(bool)
+}
+
+func (c *Config) enabled(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	case float64:
+		return t != 0
+	}
+	return false
+}
+
+// Compare the feature flags with those last observed, and when notify is set
+// supply the callbacks of any that were toggled.
+func (c *Config) toggled(notify bool) {
+	current := c.Features()
+	c.mu.Lock()
+	previous, callbacks := c.features, c.onFeature
+	c.features = current
+	c.mu.Unlock()
+	if !notify {
+		return
+	}
+	for _, f := range callbacks {
+		if current[f.name] != previous[f.name] {
+			f.fn(current[f.name])
+		}
+	}
+}
+
+// Enable the feature flag layer, which reserves the `flags` section of the
+// configuration for Feature, Features, and OnFeature even when strict or the
+// target does not declare it.  It is disabled by default, leaving `flags` to
+// applications that use the name for something else.
+func (c *Config) FeatureFlags(enabled bool) {
+	c.mu.Lock()
+	c.featureFlags = enabled
+	c.mu.Unlock()
+}
+
+// Return whether the named feature flag is enabled in the `flags` section of
+// the merged configuration, such as `{"flags": {"new-ui": true}}`, where a
+// missing flag is disabled.  Strings are parsed like strconv.ParseBool, and
+// any number other than zero is enabled.
+func (c *Config) Feature(name string) bool {
+	c.mu.RLock()
+	flags := c.featureFlags
+	c.mu.RUnlock()
+	if !flags {
+		return false
+	}
+	v, _ := c.Get(featureKey + "." + name)
+	return c.enabled(v)
+}
+
+// Return the state of every feature flag in the merged configuration, where
+// nested flags use dot-notation names.
+func (c *Config) Features() map[string]bool {
+	c.mu.RLock()
+	m, _ := c.merged[featureKey].(map[string]interface{})
+	if !c.featureFlags {
+		m = nil
+	}
+	flat := c.flatten("", m, map[string]interface{}{})
+	c.mu.RUnlock()
+	out := make(map[string]bool, len(flat))
+	for k, v := range flat {
+		out[k] = c.enabled(v)
+	}
+	return out
+}
+
+// Register a callback to receive the new state of a feature flag whenever a
+// Reload, Apply, or Set toggles it.  Flags may be overridden at runtime by
+// sending `{"flags": {"new-ui": true}}` to the Handler.
+func (c *Config) OnFeature(name string, fn func(enabled bool)) {
+	if name == "" || fn == nil {
+		return
+	}
+	c.mu.Lock()
+	c.onFeature = append(c.onFeature, feature{name, fn})
+	c.mu.Unlock()
+}
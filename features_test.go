@@ -0,0 +1,61 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeatures(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	file := `{"flags": {"new-ui": true, "beta": "false", "limits": {"raised": 1}}}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(file), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Strict(true)
+
+	// test flags are unknown until enabled
+	if e := c.Load("/tmp/app.json"); e == nil || c.Feature("new-ui") || len(c.Features()) != 0 {
+		t.Errorf("failed to disable feature flags by default, %v %v...", e, c.Features())
+	}
+
+	c.FeatureFlags(true)
+	var toggles []bool
+	c.OnFeature("beta", func(enabled bool) { toggles = append(toggles, enabled) })
+	if e := c.Load("/tmp/app.json"); e != nil || !c.Feature("new-ui") || c.Feature("beta") || !c.Feature("limits.raised") || c.Feature("missing") {
+		t.Errorf("failed to load feature flags, %v %v...", e, c.Features())
+	} else if f := c.Features(); len(f) != 3 || !f["limits.raised"] {
+		t.Errorf("failed to list feature flags, %v...", f)
+	}
+
+	// test runtime overrides from the admin endpoint notify callbacks
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"flags": {"beta": true}}`)))
+	if w.Code != http.StatusOK || !c.Feature("beta") || len(toggles) != 1 || !toggles[0] {
+		t.Errorf("failed to override feature flag, %d %v %v...", w.Code, c.Features(), toggles)
+	}
+
+	// test untoggled flags do not notify
+	c.Set("flags.new-ui", false)
+	if c.Feature("new-ui") || len(toggles) != 1 {
+		t.Errorf("failed to notify only toggled flags, %v %v...", c.Features(), toggles)
+	}
+
+	// test reloads toggle flags
+	file = `{"flags": {"new-ui": true}}`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	if e := c.Reload(); e != nil || !c.Feature("new-ui") {
+		t.Errorf("failed to reload feature flags, %v %v...", e, c.Features())
+	}
+}
@@ -0,0 +1,331 @@
+package gonf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+var readdir = ioutil.ReadDir
+
+// The extensions of supported formats, in the priority order that side by side
+// files are chosen in.
+var extensions = []string{".json", ".plist"}
+
+func (c *Config) decode(name string, data []byte) (map[string]interface{}, error) {
+	if strings.EqualFold(filepath.Ext(name), ".plist") {
+		return c.plist(data)
+	}
+	var v interface{}
+	if err := json.Unmarshal(c.comment(data), &v); err != nil {
+		return map[string]interface{}{}, err
+	}
+	return c.rooted(v)
+}
+
+func (c *Config) encode(name string) ([]byte, error) {
+	format := c.format
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	}
+	v := c.value()
+	if c.redact || len(c.references) > 0 {
+		var err error
+		if v, err = c.redacted(v); err != nil {
+			return nil, err
+		}
+	}
+	if c.keyCase != "" && c.keyCase != "insensitive" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]interface{}{}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		if err := d.Decode(&m); err != nil {
+			return nil, err
+		}
+		v = c.rekey(reflect.TypeOf(c.target), m, true)
+	}
+	var data []byte
+	var err error
+	if format == "plist" {
+		data, err = c.marshalPlist(v)
+	} else {
+		b := &bytes.Buffer{}
+		enc := json.NewEncoder(b)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(v)
+		data = c.insertComments(b.Bytes(), c.comments)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.encrypt(data)
+}
+
+// Explicitly choose the format used by Save, either "json" or "plist", instead
+// of detecting it from the extension of the configuration file.  An empty
+// format restores detection.
+func (c *Config) Format(format string) error {
+	if format != "" && format != "json" && format != "plist" {
+		return errUnknownFormat
+	}
+	c.mu.Lock()
+	c.format = format
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Config) defaultFiles() []string {
+	name := c.application()
+	c.mu.RLock()
+	patterns := c.patterns
+	c.mu.RUnlock()
+	var files []string
+	if len(patterns) > 0 {
+		for _, p := range patterns {
+			if p != "" {
+				files = append(files, filepath.FromSlash(strings.Replace(p, "{name}", name, -1)))
+			}
+		}
+	} else {
+		files = []string{filepath.Join(name, name+".json")}
+		if goos == "darwin" {
+			if home := os.Getenv("HOME"); home != "" {
+				files = append(files, filepath.Join(home, "Library", "Preferences", name+".plist"))
+			}
+			files = append(files, filepath.Join("/Library", "Preferences", name+".plist"))
+		}
+	}
+	return append(c.configurationDirectory(files), files...)
+}
+
+// Return the variant of a file name that exists, trying the extension supplied
+// and then each other supported extension in priority order, and warn when
+// several exist side by side.  The name is returned unchanged when none exist.
+func (c *Config) discover(ctx context.Context, name string) string {
+	ext, variants := filepath.Ext(name), []string{name}
+	for _, e := range extensions {
+		if strings.EqualFold(e, ext) {
+			for _, v := range extensions {
+				if v != e {
+					variants = append(variants, strings.TrimSuffix(name, ext)+v)
+				}
+			}
+		}
+	}
+	var found []string
+	for _, v := range variants {
+		if err := c.withContext(ctx, func() (e error) { _, e = c.statFile(v); return }); err == nil {
+			found = append(found, v)
+		}
+	}
+	if len(found) == 0 {
+		return name
+	} else if len(found) > 1 {
+		c.mu.Lock()
+		c.warning("multiple configuration files found, using %s instead of %s", found[0], strings.Join(found[1:], ", "))
+		c.mu.Unlock()
+	}
+	return found[0]
+}
+
+// The merged system files that were found, in ascending precedence, and a
+// signature of their modification times and contents that changes with them.
+type systemFiles struct {
+	vars    map[string]interface{}
+	origins map[string]string
+	found   []string
+	sig     string
+}
+
+func (c *Config) readSystem(ctx context.Context, f string) (systemFiles, error) {
+	s := systemFiles{vars: map[string]interface{}{}, origins: map[string]string{}}
+	var errs []error
+	for i := len(system) - 1; i >= 0; i-- {
+		var fi os.FileInfo
+		var data []byte
+		name := filepath.Join(system[i], f)
+		err := c.withContext(ctx, func() (e error) { data, e = c.read(name); return })
+		c.consulted(name, err)
+		if err != nil {
+			continue
+		}
+		var mod int64
+		if c.withContext(ctx, func() (e error) { fi, e = c.statFile(name); return }) == nil {
+			mod = fi.ModTime().UnixNano()
+		}
+		s.sig += fmt.Sprintf("%s:%d:%x;", name, mod, crc32.ChecksumIEEE(data))
+		c.mu.RLock()
+		if err = c.verify(name, data); err != nil {
+			errs = append(errs, err)
+		} else {
+			data, _, err = c.decrypt(data)
+		}
+		c.mu.RUnlock()
+		if err != nil {
+			continue
+		}
+		if m, err := c.decode(name, data); err == nil {
+			s.vars = c.merge(s.vars, m)
+			c.origins(name, m, s.origins)
+			s.found = append(s.found, name)
+		}
+	}
+	return s, errors.Join(errs...)
+}
+
+func (c *Config) readDropins(ctx context.Context) (map[string]interface{}, string, error) {
+	vars := map[string]interface{}{}
+	c.mu.RLock()
+	dir := filepath.Join(filepath.Dir(c.configFile), c.application()+".d")
+	c.mu.RUnlock()
+	var list []os.FileInfo
+	err := c.withContext(ctx, func() (e error) { list, e = c.list(dir); return })
+	c.consulted(dir, err)
+	if err != nil {
+		c.provenance("dropin", nil)
+		if ctx.Err() != nil {
+			return vars, "", err
+		}
+		return vars, "", nil
+	}
+	var sig string
+	var errs []error
+	origins := map[string]string{}
+	for _, fi := range list {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		var data []byte
+		name := filepath.Join(dir, fi.Name())
+		sig += fmt.Sprintf("%s:%d;", fi.Name(), fi.ModTime().UnixNano())
+		if err := c.withContext(ctx, func() (e error) { data, e = c.read(name); return }); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.mu.RLock()
+		err := c.verify(name, data)
+		if err == nil {
+			data, _, err = c.decrypt(data)
+		}
+		c.mu.RUnlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		m := map[string]interface{}{}
+		if err := json.Unmarshal(c.comment(data), &m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		vars = c.merge(vars, m)
+		c.origins(name, m, origins)
+	}
+	c.provenance("dropin", origins)
+	return vars, strings.TrimSuffix(sig, ";"), errors.Join(errs...)
+}
+
+func (c *Config) withDropins(ctx context.Context, vars map[string]interface{}) (map[string]interface{}, error) {
+	d, sig, err := c.readDropins(ctx)
+	c.mu.Lock()
+	c.dropins = sig
+	c.mu.Unlock()
+	return c.merge(vars, d), err
+}
+
+func (c *Config) searchPaths() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	search := c.paths
+	if search == nil {
+		search = paths
+	}
+	if c.portable && binPath != "" {
+		return append([]string{binPath}, search...)
+	}
+	return append([]string{}, search...)
+}
+
+func (c *Config) isPortable() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.portable && binPath != ""
+}
+
+// Search the directory of the executable for configuration files ahead of the
+// user and system paths, and save defaults there, for portable distributions
+// where per-user directories are undesirable.
+func (c *Config) Portable(enabled bool) {
+	c.mu.Lock()
+	c.portable = enabled
+	c.mu.Unlock()
+}
+
+// Prepend a path to search for configuration files, ahead of the defaults
+// for the operating system.
+func (c *Config) AddPath(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	search := c.paths
+	if search == nil {
+		search = paths
+	}
+	c.paths = append([]string{path}, search...)
+}
+
+// Replace the paths searched for configuration files, where the last path is
+// used to save defaults when no file is found.  Supplying no paths restores
+// the defaults for the operating system.
+func (c *Config) SetPaths(search ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(search) == 0 {
+		c.paths = nil
+		return
+	}
+	c.paths = append([]string{}, search...)
+}
+
+// Replace the candidate file names searched for in each path when Load is
+// called without any, such as `config.json` or `{name}/settings.json`, where
+// `{name}` is replaced by the application name.  The first is used to save
+// defaults when no file is found.  Supplying no names restores the default of
+// `{name}/{name}.json`.
+func (c *Config) Filenames(patterns ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(patterns) == 0 {
+		c.patterns = nil
+		return
+	}
+	c.patterns = append([]string{}, patterns...)
+}
+
+// Set the permissions used by Save for created directories and for the file,
+// where a zero mode keeps the default.  The file mode is enforced on every
+// Save, even when the file already exists.  On windows only the write
+// permission of the file is honored.
+func (c *Config) Permissions(dir, file os.FileMode) {
+	c.mu.Lock()
+	c.dirMode, c.fileMode = dir.Perm(), file.Perm()
+	c.mu.Unlock()
+}
+
+// Set the user and group that own the file written by Save.  This requires
+// sufficient privileges, and is ignored on windows.
+func (c *Config) Owner(uid, gid int) {
+	c.mu.Lock()
+	c.owner, c.uid, c.gid = true, uid, gid
+	c.mu.Unlock()
+}
@@ -0,0 +1,351 @@
+package gonf
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDropins(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	dir := filepath.Join("/etc", appName, appName+".d")
+	files := map[string]string{
+		filepath.Join("/etc", appName, appName+".json"): `{"OptionString": "main", "EnvString": "main", "EnvNumber": 1}`,
+		filepath.Join(dir, "10-first.json"):             `{"OptionString": "first", "EnvString": "first"}`,
+		filepath.Join(dir, "20-second.json"):            `{"EnvString": "second" /* comment */}`,
+		filepath.Join(dir, "30-bad.json"):               `not json`,
+		filepath.Join(dir, "readme.txt"):                `{"EnvNumber": 99}`,
+	}
+	modTime := time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(n string) ([]byte, error) {
+		if d, ok := files[n]; ok {
+			return []byte(d), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	readdir = func(d string) ([]os.FileInfo, error) {
+		if d != dir {
+			return nil, os.ErrNotExist
+		}
+		return []os.FileInfo{
+			&mockStat{name: "10-first.json", modTime: modTime},
+			&mockStat{name: "20-second.json", modTime: modTime},
+			&mockStat{name: "30-bad.json", modTime: modTime},
+			&mockStat{name: "nested.json", dir: true},
+			&mockStat{name: "readme.txt", modTime: modTime},
+		}, nil
+	}
+	defer func() { readdir = ioutil.ReadDir }()
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Load(filepath.Join("/etc", appName, appName+".json")); e == nil || mc.OptionString != "first" || mc.EnvString != "second" || mc.EnvNumber != 1 {
+		t.Errorf("failed to merge drop-in files, %v %+v...", e, mc)
+	}
+
+	// test reload when only a drop-in has changed
+	delete(files, filepath.Join(dir, "30-bad.json"))
+	files[filepath.Join(dir, "20-second.json")] = `{"EnvString": "changed"}`
+	readdir = func(string) ([]os.FileInfo, error) {
+		return []os.FileInfo{&mockStat{name: "20-second.json", modTime: modTime.Add(time.Second)}}, nil
+	}
+	if e := c.Reload(); e != nil || mc.EnvString != "changed" || mc.OptionString != "main" {
+		t.Errorf("failed to reload changed drop-in files, %v %+v...", e, mc)
+	}
+
+	// test reload without changes
+	if e := c.Reload(); e == nil {
+		t.Error("failed to capture unchanged files...")
+	}
+}
+
+func TestConfigOverride(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(n string) ([]byte, error) {
+		if n == "/custom/cli.json" || n == "/custom/env.json" {
+			return []byte(`{"OptionString": "` + n + `"}`), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+
+	// test command line option
+	os.Args = []string{"app", "--config", "/custom/cli.json"}
+	if e := c.Load(); e != nil || c.ConfigFile() != "/custom/cli.json" || mc.OptionString != "/custom/cli.json" {
+		t.Errorf("failed to load file from command line option, %v...", e)
+	}
+
+	// test environment variable
+	os.Args = []string{"app"}
+	os.Setenv("GONF_CONFIG", "/custom/env.json")
+	if e := c.Load(); e != nil || c.ConfigFile() != "/custom/env.json" || mc.OptionString != "/custom/env.json" {
+		t.Errorf("failed to load file from environment variable, %v...", e)
+	}
+
+	// test command line precedence and missing file
+	os.Args = []string{"app", "--config=/custom/missing.json"}
+	if e := c.Load(); e == nil || c.ConfigFile() != "/custom/missing.json" {
+		t.Error("failed to report missing configuration file...")
+	}
+
+	// test registered option takes precedence
+	c.Add("EnvString", "", "", "--config")
+	os.Clearenv()
+	os.Args = []string{"app", "--config=registered"}
+	c.parseOptions()
+	if c.override != "" {
+		t.Error("failed to defer to registered option...")
+	}
+}
+
+func TestPaths(t *testing.T) {
+	c := &Config{}
+	if p := c.searchPaths(); len(p) != len(paths) {
+		t.Error("failed to default to package paths...")
+	}
+	c.AddPath("/first")
+	if p := c.searchPaths(); len(p) != len(paths)+1 || p[0] != "/first" {
+		t.Error("failed to prepend path...")
+	}
+	c.SetPaths("/one", "/two")
+	if p := c.searchPaths(); len(p) != 2 || p[0] != "/one" || p[1] != "/two" {
+		t.Error("failed to replace paths...")
+	}
+
+	// test saving defaults to the last path
+	os.Args = []string{}
+	os.Clearenv()
+	var created string
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return nil, os.ErrNotExist }
+	mkdirall = func(string, os.FileMode) error { return nil }
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		created = filepath.Join(dir, pattern)
+		return nil, os.ErrPermission
+	}
+	c.Target(&mockConfig{})
+	c.Load("test.json")
+	if created != filepath.Join("/two", ".test.json.*") {
+		t.Errorf("failed to save to last path, %s...", created)
+	}
+
+	c.SetPaths()
+	if p := c.searchPaths(); len(p) != len(paths) {
+		t.Error("failed to restore package paths...")
+	}
+}
+
+func TestFilenames(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	var searched []string
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(name string) ([]byte, error) {
+		searched = append(searched, name)
+		return nil, os.ErrNotExist
+	}
+	createTemp = func(string, string) (*os.File, error) { return nil, os.ErrPermission }
+	defer func() { createTemp = ioutil.TempFile }()
+	defer func(s []string) { system = s }(system)
+	system = nil
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Name("myapp")
+	c.SetPaths("/one", "/two")
+	c.Filenames("config.json", "", "{name}/settings.json")
+	c.Load()
+	expected := []string{"/one/config.json", "/two/config.json", "/one/myapp/settings.json", "/two/myapp/settings.json"}
+	if strings.Join(searched, ",") != filepath.FromSlash(strings.Join(expected, ",")) || c.ConfigFile() != filepath.FromSlash("/two/config.json") {
+		t.Errorf("failed to search custom file names, %v %s...", searched, c.ConfigFile())
+	}
+
+	c.Filenames()
+	if f := c.defaultFiles(); f[0] != filepath.Join("myapp", "myapp.json") {
+		t.Errorf("failed to restore default file names, %v...", f)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func(s []string) { system = s }(system)
+	system = nil
+
+	plist := `<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict><key>EnvString</key><string>plist</string></dict></plist>`
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Name("myapp")
+	c.SetPaths("/one", "/two")
+	c.FS(fstest.MapFS{
+		"one/myapp/myapp.plist": {Data: []byte(plist)},
+		"two/myapp/myapp.json":  {Data: []byte(`{"EnvString": "json"}`)},
+		"two/myapp/myapp.plist": {Data: []byte(plist)},
+		"two/other.txt":         {Data: []byte(`{}`)},
+	})
+
+	// test other formats are found in earlier paths
+	if e := c.Load(); e != nil || mc.EnvString != "plist" || c.ConfigFile() != filepath.FromSlash("/one/myapp/myapp.plist") {
+		t.Errorf("failed to discover other formats, %v %s...", e, c.ConfigFile())
+	}
+
+	// test side by side files are chosen by priority with a warning
+	if f := c.discover(context.Background(), filepath.FromSlash("/two/myapp/myapp.json")); f != filepath.FromSlash("/two/myapp/myapp.json") || len(c.pending) != 1 {
+		t.Errorf("failed to choose json by priority, %s %v...", f, c.pending)
+	}
+	if f := c.discover(context.Background(), filepath.FromSlash("/two/myapp/myapp.plist")); f != filepath.FromSlash("/two/myapp/myapp.plist") {
+		t.Errorf("failed to prefer the extension supplied, %s...", f)
+	}
+
+	// test unsupported extensions and missing files are unchanged
+	if f := c.discover(context.Background(), filepath.FromSlash("/two/other.txt")); f != filepath.FromSlash("/two/other.txt") {
+		t.Errorf("failed to keep unsupported extension, %s...", f)
+	}
+	if f := c.discover(context.Background(), filepath.FromSlash("/two/missing.json")); f != filepath.FromSlash("/two/missing.json") {
+		t.Errorf("failed to keep missing file, %s...", f)
+	}
+}
+
+func TestPortable(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func(b string, s []string) { binPath, system = b, s }(binPath, system)
+	binPath, system = filepath.FromSlash("/usb/app"), []string{"/etc/xdg"}
+	var searched []string
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(name string) ([]byte, error) {
+		searched = append(searched, name)
+		return nil, os.ErrNotExist
+	}
+	createTemp = func(string, string) (*os.File, error) { return nil, os.ErrPermission }
+	defer func() { createTemp = ioutil.TempFile }()
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.AddPath("/first")
+	c.Portable(true)
+	c.Load("app.json")
+	expected := filepath.Join(binPath, "app.json")
+	if len(searched) < 3 || searched[0] != filepath.Join("/etc/xdg", "app.json") || searched[1] != expected || searched[2] != filepath.Join("/first", "app.json") || c.ConfigFile() != expected {
+		t.Errorf("failed to prefer the executable directory, %v %s...", searched, c.ConfigFile())
+	}
+	if p := c.searchPaths(); len(p) != len(paths)+2 || p[0] != binPath || p[1] != "/first" {
+		t.Errorf("failed to prepend the executable directory, %v...", p)
+	}
+
+	c.Portable(false)
+	if p := c.searchPaths(); p[0] != "/first" {
+		t.Errorf("failed to restore search paths, %v...", p)
+	}
+}
+
+func TestSystemPaths(t *testing.T) {
+	system = []string{"/etc/high", "/etc/low"}
+	defer func() { system = nil }()
+	os.Args = []string{}
+	os.Clearenv()
+	name := filepath.Join(appName, appName+".json")
+	files := map[string]string{
+		filepath.Join("/etc/high", name): `{"OptionString": "high", "EnvString": "high"}`,
+		filepath.Join("/etc/low", name):  `{"OptionString": "low", "EnvString": "low", "EnvNumber": 1}`,
+	}
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(n string) ([]byte, error) {
+		if d, ok := files[n]; ok {
+			return []byte(d), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	created := false
+	createTemp = func(string, string) (*os.File, error) { created = true; return nil, os.ErrPermission }
+
+	// test system files without a user file
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.SetPaths("/home/user")
+	if e := c.Load(); e != nil || created || mc.OptionString != "high" || mc.EnvNumber != 1 || c.ConfigFile() != filepath.Join("/etc/high", name) {
+		t.Errorf("failed to load system files, %v %+v...", e, mc)
+	}
+
+	// test edited system files apply on reload without a user file
+	files[filepath.Join("/etc/low", name)] = `{"OptionString": "low", "EnvString": "low", "EnvNumber": 2}`
+	if e := c.Reload(); e != nil || mc.EnvNumber != 2 || mc.OptionString != "high" {
+		t.Errorf("failed to reload system files, %v %+v...", e, mc)
+	}
+
+	// test user file merged over system files
+	files[filepath.Join("/home/user", name)] = `{"OptionString": "user"}`
+	if e := c.Load(); e != nil || mc.OptionString != "user" || mc.EnvString != "high" || mc.EnvNumber != 2 {
+		t.Errorf("failed to merge user file over system files, %v %+v...", e, mc)
+	}
+
+	// test reload retains system files
+	mc.EnvString = ""
+	files[filepath.Join("/home/user", name)] = `{"OptionString": "reloaded"}`
+	if e := c.Reload(); e != nil || mc.OptionString != "reloaded" || mc.EnvString != "high" {
+		t.Errorf("failed to retain system files on reload, %v %+v...", e, mc)
+	}
+
+	// test edited system files apply on reload beneath the user file
+	files[filepath.Join("/etc/high", name)] = `{"OptionString": "high", "EnvString": "edited"}`
+	if e := c.Reload(); e != nil || mc.OptionString != "reloaded" || mc.EnvString != "edited" || c.source("EnvString") != filepath.Join("/etc/high", name) {
+		t.Errorf("failed to reload edited system files, %v %+v...", e, mc)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	d, e := ioutil.TempDir(os.TempDir(), "gonf")
+	if e != nil {
+		t.Fatal("failed to acquire temporary directory...")
+	}
+	defer os.RemoveAll(d)
+	cf := filepath.Join(d, "nested", "gonf.json")
+
+	var dirMode os.FileMode
+	var owner []int
+	mkdirall = func(p string, m os.FileMode) error { dirMode = m; return os.MkdirAll(p, m) }
+	chown = func(_ string, uid, gid int) error { owner = []int{uid, gid}; return nil }
+	stat, createTemp, rename = os.Stat, ioutil.TempFile, os.Rename
+	defer func() { mkdirall, chown = os.MkdirAll, os.Chown }()
+
+	c := &Config{configFile: cf}
+	c.Permissions(0750, 0640)
+	c.Owner(1000, 100)
+	if e := c.Save(); e != nil {
+		t.Fatalf("failed to save with permissions, %v...", e)
+	}
+	if fi, _ := os.Stat(cf); dirMode != 0750 || fi.Mode().Perm() != 0640 {
+		t.Errorf("failed to apply permissions, %v %v...", dirMode, fi.Mode())
+	}
+	if goos != "windows" && (len(owner) != 2 || owner[0] != 1000 || owner[1] != 100) {
+		t.Error("failed to apply ownership...")
+	}
+
+	// test enforced on existing files
+	os.Chmod(cf, 0644)
+	c.Save()
+	if fi, _ := os.Stat(cf); fi.Mode().Perm() != 0640 {
+		t.Error("failed to enforce permissions on existing file...")
+	}
+
+	// test ownership errors
+	chown = func(string, int, int) error { return mockError }
+	if goos != "windows" && c.Save() == nil {
+		t.Error("failed to capture ownership error...")
+	}
+}
@@ -0,0 +1,25 @@
+package gonf
+
+import "log/slog"
+
+// Return ErrFrozen, with a warning, when the configuration has been frozen.
+func (c *Config) thawed(operation string) error {
+	c.mu.RLock()
+	frozen := c.frozen
+	c.mu.RUnlock()
+	if !frozen {
+		return nil
+	}
+	c.notify(slog.LevelWarn, "rejected %s of frozen configuration", operation)
+	return ErrFrozen
+}
+
+// Permanently reject any further Load, Reload, Set, or Apply with ErrFrozen,
+// for security-sensitive processes that must not accept configuration changes
+// at runtime once they have started.  The http handlers respond with 403
+// Forbidden.
+func (c *Config) Freeze() {
+	c.mu.Lock()
+	c.frozen = true
+	c.mu.Unlock()
+}
@@ -0,0 +1,52 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(`{"OptionString": "file"}`), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Load("/tmp/gonf.json"); e != nil || mc.OptionString != "file" {
+		t.Fatalf("failed to load before freezing, %v...", e)
+	}
+	c.Freeze()
+
+	// test every change is rejected
+	for name, fn := range map[string]func() error{
+		"load":   func() error { return c.Load("/tmp/gonf.json") },
+		"reload": c.Reload,
+		"set":    func() error { return c.Set("OptionString", "set") },
+		"apply":  func() error { return c.Apply(map[string]interface{}{"OptionString": "apply"}) },
+	} {
+		if e := fn(); e != ErrFrozen || mc.OptionString != "file" {
+			t.Errorf("failed to reject %s, %v %s...", name, e, mc.OptionString)
+		}
+	}
+
+	// test handlers respond with forbidden
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"OptionString": "api"}`)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("failed to forbid overrides, %d...", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	c.ReloadHandler("secret").ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("failed to forbid reloads, %d...", w.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package gonf
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Boxes the file system so that atomic.Value always stores the same concrete
+// type, and so it may be read while the lock is already held.
+type fileSystem struct {
+	fs.FS
+}
+
+func (c *Config) fsys() fs.FS {
+	f, _ := c.filesystem.Load().(fileSystem)
+	return f.FS
+}
+
+// Translate a path into one that is valid for fs.FS, by removing any volume
+// and leading slash.
+func (c *Config) fsPath(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(strings.TrimPrefix(name, filepath.VolumeName(name)))), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (c *Config) statFile(name string) (os.FileInfo, error) {
+	if f := c.fsys(); f != nil {
+		return fs.Stat(f, c.fsPath(name))
+	}
+	return stat(name)
+}
+
+func (c *Config) read(name string) ([]byte, error) {
+	var data []byte
+	var err error
+	if f := c.fsys(); f != nil {
+		data, err = fs.ReadFile(f, c.fsPath(name))
+	} else {
+		data, err = readfile(name)
+	}
+	c.bytesRead.Add(int64(len(data)))
+	return data, err
+}
+
+func (c *Config) list(dir string) ([]os.FileInfo, error) {
+	f := c.fsys()
+	if f == nil {
+		return readdir(dir)
+	}
+	entries, err := fs.ReadDir(f, c.fsPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if fi, err := e.Info(); err == nil {
+			infos = append(infos, fi)
+		}
+	}
+	return infos, nil
+}
+
+// Read configuration files, drop-ins, and system files from the supplied
+// file system, such as an embed.FS, instead of the operating system.  Paths
+// are translated by removing the leading slash, so combine it with
+// SetPaths("/") to find `app.json` at the root.  Since fs.FS is read-only,
+// defaults are not saved when no file is found, and Save returns an error.
+// A nil file system restores the operating system.
+func (c *Config) FS(fsys fs.FS) {
+	c.filesystem.Store(fileSystem{fsys})
+}
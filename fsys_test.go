@@ -0,0 +1,59 @@
+package gonf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFS(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Args = []string{}
+	stat, readfile = nil, nil
+	defer func() { readdir = ioutil.ReadDir }()
+	readdir = nil
+	createTemp = nil
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.SetPaths("/")
+	c.FS(fstest.MapFS{
+		"gonf.json":                  {Data: []byte(`{"EnvString": "embedded", "OptionNumber": 1}`), ModTime: time.Now()},
+		appName + ".d/override.json": {Data: []byte(`{"OptionNumber": 2}`)},
+		appName + ".d/ignored.txt":   {Data: []byte(`not json`)},
+		"embedded.json":              {Data: []byte(`{"EnvString": "unmodified"}`)},
+	})
+
+	// test files and drop-ins are read from the file system
+	if e := c.Load("gonf.json"); e != nil || mc.EnvString != "embedded" || mc.OptionNumber != 2 || c.ConfigFile() != "/gonf.json" {
+		t.Errorf("failed to load from file system, %v %s %v...", e, c.ConfigFile(), mc.EnvString)
+	}
+	if e := c.Reload(); e != errNoChanges {
+		t.Errorf("failed to stat file system for reload, %v...", e)
+	}
+
+	// test files without a modification time are always read
+	if e := c.Load("embedded.json"); e != nil || mc.EnvString != "unmodified" {
+		t.Errorf("failed to load file without modification time, %v...", e)
+	}
+	if e := c.Reload(); e != nil {
+		t.Errorf("failed to reload file without modification time, %v...", e)
+	}
+
+	// test missing files are not saved
+	if e := c.Load("missing.json"); e != nil {
+		t.Errorf("failed to skip saving to a read-only file system, %v...", e)
+	}
+	if e := c.Save(); !errors.Is(e, errReadOnly) {
+		t.Errorf("failed to reject saving to a read-only file system, %v...", e)
+	}
+
+	if p := c.fsPath(`/etc/app/../app.json`); p != "etc/app.json" || c.fsPath("/") != "." {
+		t.Errorf("failed to translate paths, %s...", p)
+	}
+}
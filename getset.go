@@ -0,0 +1,55 @@
+package gonf
+
+func (c *Config) remember(vars map[string]interface{}) {
+	c.mu.Lock()
+	if c.inPlace && c.merged != nil {
+		c.mergeInto(c.merged, vars)
+	} else if c.inPlace {
+		c.merged = c.clone(vars)
+	} else {
+		c.merged = c.merge(c.merged, vars)
+	}
+	c.mu.Unlock()
+}
+
+// Return the value of a dot-notation key, or JSON Pointer, from the merged
+// configuration, which includes keys that are not represented by the target,
+// or otherwise from the current value of the target.
+func (c *Config) Get(name string) (interface{}, bool) {
+	keys, err := c.path(name)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	v, ok := c.lookupPath(c.merged, keys)
+	c.mu.RUnlock()
+	if ok {
+		return v, true
+	}
+	c.mu.RLock()
+	m := c.document()
+	c.mu.RUnlock()
+	return c.lookupPath(m, keys)
+}
+
+// Return a copy of the merged configuration from the last Load, before it was
+// applied to the target, including keys that are not represented by it, such
+// as plugin sections or arbitrary user metadata.
+func (c *Config) Map() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clone(c.merged)
+}
+
+// Set the value of a dot-notation key, or JSON Pointer, in the merged
+// configuration, and apply it to the target using the same validation as
+// Load.  Keys that are not represented by the target are retained for Get.
+func (c *Config) Set(name string, value interface{}) error {
+	keys, err := c.path(name)
+	if err != nil {
+		return err
+	}
+	vars := map[string]interface{}{}
+	c.setPath(vars, keys, value)
+	return c.Apply(vars)
+}
@@ -0,0 +1,73 @@
+package gonf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGetSet(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"OptionString": "file", "plugin": {"name": "extra", "port": 8080}, "hosts": {"example.com": {"a/b~c": 1}}}`), nil
+	}
+
+	mc := &mockConfig{OptionBool: true}
+	c := &Config{}
+	c.Target(mc)
+	c.Load("/tmp/app.json")
+	if v, ok := c.Get("plugin.port"); !ok || v != float64(8080) {
+		t.Errorf("failed to get key missing from the target, %v...", v)
+	}
+	if v, ok := c.Get("OptionBool"); !ok || v != true {
+		t.Errorf("failed to get value from the target, %v...", v)
+	}
+	if _, ok := c.Get("missing.key"); ok {
+		t.Error("failed to report missing key...")
+	}
+
+	// test the merged map is a copy including unknown keys
+	m := c.Map()
+	if p, ok := m["plugin"].(map[string]interface{}); !ok || p["name"] != "extra" || m["OptionString"] != "file" {
+		t.Errorf("failed to expose merged map, %v...", m)
+	} else if p["name"] = "changed"; c.Map()["plugin"].(map[string]interface{})["name"] != "extra" {
+		t.Error("failed to copy merged map...")
+	}
+
+	// test set of target and merged keys
+	if e := c.Set("ExplicitComposite.DepthByOption", 5); e != nil || mc.ExplicitComposite.DepthByOption != 5 {
+		t.Errorf("failed to set target value, %v %+v...", e, mc)
+	}
+	if e := c.Set("plugin.port", 8081); e != nil {
+		t.Errorf("failed to set merged value, %v...", e)
+	}
+	if v, _ := c.Get("plugin.port"); v != 8081 {
+		t.Errorf("failed to retain merged value, %v...", v)
+	}
+	if v, _ := c.Get("plugin.name"); v != "extra" {
+		t.Errorf("failed to preserve sibling values, %v...", v)
+	}
+	if c.Set("bad..name", 1) == nil {
+		t.Error("failed to reject bad name...")
+	}
+
+	// test json pointers address keys containing dots and slashes
+	if v, ok := c.Get("/hosts/example.com/a~1b~0c"); !ok || v != float64(1) {
+		t.Errorf("failed to get json pointer, %v...", v)
+	}
+	if v, ok := c.Get("/ExplicitComposite/DepthByOption"); !ok || v != 5 {
+		t.Errorf("failed to get json pointer of a target value, %v...", v)
+	}
+	if e := c.Set("/hosts/example.com/a~1b~0c", 2); e != nil {
+		t.Errorf("failed to set json pointer, %v...", e)
+	} else if v, _ := c.Get("/hosts/example.com/a~1b~0c"); v != 2 {
+		t.Errorf("failed to retain json pointer value, %v...", v)
+	}
+	for _, bad := range []string{"/", "/hosts//port", "/hosts/~2"} {
+		if c.Set(bad, 1) == nil {
+			t.Errorf("failed to reject bad json pointer %q...", bad)
+		}
+	}
+}
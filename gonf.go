@@ -6,14 +6,21 @@
 // to provide a clear non-verbose implementation, while automatically dealing
 // with common expectations.
 //
-// It automatically detects the application name using os.Args[0].  It also
-// determines the true path to the application by resolving symbolic links.
+// It automatically detects the application name using os.Args[0], unless
+// it is overridden.  It also determines the true path to the application by
+// resolving symbolic links.
 //
 // It keeps track of two paths as a package global for dealing with
 // configuration files; the relative path to the application, and a
 // sane default per operating system.  On windows it checks %APPDATA%,
 // on mac it checks ~/Library/Preferences, and for the rest it uses
 // $XDG_HOME_PATH with a fallback of ~/.config.
+//
+// Elsewhere it also honors the $XDG_CONFIG_DIRS system list, with a fallback
+// of /etc/xdg, whose files are merged beneath the user configuration file.
+//
+// On darwin it also recognizes property list files named after the
+// application in ~/Library/Preferences and /Library/Preferences.
 package gonf
 
 import (
@@ -26,19 +33,23 @@ import (
 var (
 	appPath = os.Args[0]
 	appName = strings.TrimSuffix(filepath.Base(appPath), filepath.Ext(appPath))
+	binPath string
 	paths   []string
+	system  []string
+	goos    = runtime.GOOS
 )
 
 func init() {
 	if p, e := filepath.EvalSymlinks(appPath); e == nil {
 		if a, e := filepath.Abs(p); e == nil {
-			paths = append(paths, filepath.Join(filepath.Dir(a)))
+			binPath = filepath.Dir(a)
+			paths = append(paths, binPath)
 		}
 	}
 	if appData := os.Getenv("APPDATA"); appData != "" {
 		paths = append(paths, filepath.Join(appData, "Roaming"))
 	} else if home := os.Getenv("HOME"); home != "" {
-		if runtime.GOOS == "darwin" {
+		if goos == "darwin" {
 			paths = append(paths, filepath.Join(home, "Library", "Preferences"))
 		} else if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
 			paths = append(paths, xdg)
@@ -46,4 +57,11 @@ func init() {
 			paths = append(paths, filepath.Join(home, ".config"))
 		}
 	}
+	if goos == "windows" || goos == "darwin" {
+		return
+	} else if dirs := os.Getenv("XDG_CONFIG_DIRS"); dirs != "" {
+		system = filepath.SplitList(dirs)
+	} else {
+		system = []string{"/etc/xdg"}
+	}
 }
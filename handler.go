@@ -0,0 +1,132 @@
+package gonf
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func (c *Config) mask(prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if c.sensitiveKey(path) {
+			m[k] = redacted
+		} else if o, ok := v.(map[string]interface{}); ok {
+			c.mask(path, o)
+		}
+	}
+}
+
+// Return the current configuration as nested json values, with sensitive
+// values masked, for services that expose it over other transports.
+func (c *Config) Values() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := c.document()
+	c.mask("", m)
+	return m
+}
+
+// Apply overrides to the target using the same validation as Load, and
+// trigger any OnReload callbacks.  If validation or conversion fails, the
+// target is restored and the overrides are not kept for later reloads.
+func (c *Config) Apply(overrides map[string]interface{}) error {
+	if err := c.thawed("change"); err != nil {
+		return err
+	}
+	overrides = c.normalize(c.migrate(c.mapKeys(overrides)))
+	if err := c.validate(overrides); err != nil {
+		return err
+	}
+	c.mu.RLock()
+	prior := c.document()
+	c.mu.RUnlock()
+	before := c.snapshot()
+	err := c.to(overrides)
+	if err != nil {
+		c.to(prior)
+	}
+	c.flush()
+	if err != nil {
+		return err
+	}
+	c.remember(overrides)
+	c.mu.Lock()
+	if c.sources == nil {
+		c.sources = map[string]map[string]string{}
+	}
+	if c.sources["api"] == nil {
+		c.sources["api"] = map[string]string{}
+	}
+	c.origins("api", overrides, c.sources["api"])
+	c.mu.Unlock()
+	c.record()
+	c.changed(c.diff(before, c.snapshot()))
+	return nil
+}
+
+// Create an http.Handler for an existing admin mux, which responds to GET
+// with the current configuration as json, with sensitive values masked.
+// A json object sent with POST or PUT is applied as overrides using the same
+// validation as Load, and triggers any OnReload callbacks, before responding
+// with the updated configuration.  Invalid overrides are rejected entirely.
+func (c *Config) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost, http.MethodPut:
+			overrides := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if err := c.Apply(overrides); err == ErrFrozen {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(c.Values())
+	})
+}
+
+// Create an http.Handler that triggers Reload when sent a POST with the token
+// as a bearer credential in the Authorization header, so orchestration systems
+// can push changes instead of sending signals into containers.  It responds
+// with no content when the reload succeeds or nothing has changed, and with
+// the error otherwise.  An empty token rejects every request.
+func (c *Config) ReloadHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if token == "" || !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if err := c.Reload(); err == ErrFrozen {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if err != nil && err != errNoChanges {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,120 @@
+package gonf
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	mc := &mockConfig{OptionString: "a", EnvString: "secret"}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	c.Add("OptionString", "", "", "--string")
+	c.Choices("OptionString", "a", "b")
+	var changes ChangeSet
+	c.OnReload(func(cs ChangeSet) { changes = cs })
+	h := c.Handler()
+
+	// test current configuration
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var m map[string]interface{}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &m) != nil || m["OptionString"] != "a" || m["EnvString"] != redacted {
+		t.Errorf("failed to serve configuration, %d %s...", w.Code, w.Body)
+	}
+
+	// test applying overrides
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"OptionString": "b", "ExplicitComposite": {"DepthByOption": 4}}`)))
+	if w.Code != http.StatusOK || mc.OptionString != "b" || mc.ExplicitComposite.DepthByOption != 4 || len(changes) != 2 || c.source("OptionString") != "api" {
+		t.Errorf("failed to apply overrides, %d %s %+v...", w.Code, w.Body, changes)
+	}
+
+	// test rejected overrides
+	for _, body := range []string{`{"OptionString": "c", "OptionBool": true}`, `not json`} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest || mc.OptionString != "b" || mc.OptionBool {
+			t.Errorf("failed to reject invalid overrides, %d %s...", w.Code, w.Body)
+		}
+	}
+
+	// test unsupported methods
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") == "" {
+		t.Errorf("failed to reject unsupported method, %d...", w.Code)
+	}
+}
+
+func TestApply(t *testing.T) {
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	if e := c.Apply(map[string]interface{}{"EnvString": "secret", "OptionNumber": 2}); e != nil || mc.EnvString != "secret" || mc.OptionNumber != 2 {
+		t.Errorf("failed to apply overrides, %v %+v...", e, mc)
+	}
+	if v := c.Values(); v["EnvString"] != redacted || v["OptionNumber"] != float64(2) {
+		t.Errorf("failed to return masked values, %v...", v)
+	}
+	// test a failed apply restores the target and keeps nothing
+	if e := c.Apply(map[string]interface{}{"EnvString": "partial", "OptionNumber": "many"}); !errors.Is(e, ErrCast) || mc.EnvString != "secret" || mc.OptionNumber != 2 {
+		t.Errorf("failed to restore the target after a failed apply, %v %+v...", e, mc)
+	}
+	if v, _ := c.Get("EnvString"); v != "secret" {
+		t.Errorf("failed to discard the overrides of a failed apply, %v...", v)
+	}
+}
+
+func TestReloadHandler(t *testing.T) {
+	data := []byte(`{"OptionString": "reloaded"}`)
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return data, nil }
+
+	mc := &mockConfig{}
+	c := &Config{configFile: "test.gonf.json"}
+	c.Target(mc)
+	h := c.ReloadHandler("secret")
+	request := func(method, auth string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "/", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// test methods and credentials are checked
+	if w := request(http.MethodGet, "Bearer secret"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("failed to reject method, %d...", w.Code)
+	}
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		if w := request(http.MethodPost, auth); w.Code != http.StatusUnauthorized || mc.OptionString != "" {
+			t.Errorf("failed to reject credentials %q, %d...", auth, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	c.ReloadHandler("").ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("failed to reject every request without a token, %d...", w.Code)
+	}
+
+	// test reload
+	if w := request(http.MethodPost, "Bearer secret"); w.Code != http.StatusNoContent || mc.OptionString != "reloaded" {
+		t.Errorf("failed to reload, %d %s...", w.Code, w.Body)
+	}
+
+	// test reload errors
+	data = []byte(`not json`)
+	if w := request(http.MethodPost, "Bearer secret"); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed to report reload error, %d...", w.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package gonf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Defines how Load behaves when help, version, or the effective configuration
+// is requested, following the conventions of flag.FlagSet.
+type ErrorHandling int
+
+const (
+	// Terminate the application, which is the default.
+	ExitOnError ErrorHandling = iota
+
+	// Return ErrHelp, or the errors found while checking, from Load.
+	ContinueOnError
+
+	// Panic with ErrHelp, or the errors found while checking.
+	PanicOnError
+)
+
+type writer struct{ io.Writer }
+
+// Stop after help, version, or a check according to the ErrorHandling, and
+// return the reason when the application should continue.
+func (c *Config) halt(code int, reason error) error {
+	c.mu.Lock()
+	handling := c.handling
+	if handling == ContinueOnError && c.halted == nil {
+		c.halted = reason
+	}
+	c.mu.Unlock()
+	switch handling {
+	case ContinueOnError:
+		return reason
+	case PanicOnError:
+		panic(reason)
+	}
+	exit(code)
+	return nil
+}
+
+func (c *Config) writer() io.Writer {
+	if w, _ := c.output.Load().(writer); w.Writer != nil {
+		return w.Writer
+	}
+	return stdout
+}
+
+func (c *Config) printf(format string, args ...interface{}) {
+	if w, _ := c.output.Load().(writer); w.Writer != nil {
+		fmt.Fprintf(w.Writer, format, args...)
+		return
+	}
+	fmtPrintf(format, args...)
+}
+
+// Set how Load responds to help, version, and the `--check-config` and
+// `--dump-config` options, so that it may be used inside servers and tests
+// without terminating the process.
+func (c *Config) ErrorHandling(handling ErrorHandling) {
+	c.mu.Lock()
+	c.handling = handling
+	c.mu.Unlock()
+}
+
+// Write help, version, and configuration reports to w instead of standard
+// output.
+func (c *Config) Output(w io.Writer) {
+	c.output.Store(writer{w})
+}
@@ -0,0 +1,75 @@
+package gonf
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestErrorHandling(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	exitCode := -1
+	exit = func(i int) { exitCode = i }
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) {
+		t.Error("failed to stop before reading files...")
+		return nil, os.ErrNotExist
+	}
+
+	out := &bytes.Buffer{}
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Description("testing error handling")
+	c.Version("1.0")
+	c.Output(out)
+	c.ErrorHandling(ContinueOnError)
+
+	// test help and version return ErrHelp without exiting
+	for _, arg := range []string{"--help", "-V"} {
+		out.Reset()
+		os.Args = []string{"app", arg}
+		if e := c.Load(); e != ErrHelp || exitCode != -1 || out.Len() == 0 {
+			t.Errorf("failed to continue after %s, %v %d...", arg, e, exitCode)
+		}
+	}
+
+	// test check reports to the output and returns its errors
+	out.Reset()
+	c.FS(fstest.MapFS{})
+	c.Add("OptionString", "", "", "--string")
+	c.Choices("OptionString", "a", "b")
+	os.Args = []string{"app", "--check-config", "--string", "c"}
+	if e := c.Load(); !errors.Is(e, ErrInvalidChoice) || exitCode != -1 || !strings.Contains(out.String(), "configuration is invalid") {
+		t.Errorf("failed to continue after check, %v %s...", e, out.String())
+	}
+
+	// test dump returns ErrHelp when there are no errors
+	out.Reset()
+	os.Args = []string{"app", "--dump-config"}
+	if e := c.Load(); e != ErrHelp || !strings.Contains(out.String(), "# default") {
+		t.Errorf("failed to continue after dump, %v %s...", e, out.String())
+	}
+
+	// test panic
+	c.ErrorHandling(PanicOnError)
+	os.Args = []string{"app", "--help"}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrHelp {
+				t.Errorf("failed to panic after help, %v...", r)
+			}
+		}()
+		c.Load()
+	}()
+
+	// test exit remains the default
+	c.ErrorHandling(ExitOnError)
+	if c.Load(); exitCode != 0 {
+		t.Errorf("failed to exit after help, %d...", exitCode)
+	}
+}
@@ -0,0 +1,202 @@
+package gonf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var errNoRevision = errors.New("no configuration revision to roll back to...")
+
+// A configuration that was successfully loaded or applied, where the values
+// are the merged configuration, the sources are the origin of each key by
+// layer, and the fingerprint matches Fingerprint.
+type Revision struct {
+	Time        time.Time                    `json:"time"`
+	Fingerprint string                       `json:"fingerprint"`
+	Values      map[string]interface{}       `json:"values"`
+	Sources     map[string]map[string]string `json:"sources,omitempty"`
+	file        string
+}
+
+func (c *Config) copySources(in map[string]map[string]string) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(in))
+	for l, origins := range in {
+		out[l] = make(map[string]string, len(origins))
+		for k, o := range origins {
+			out[l][k] = o
+		}
+	}
+	return out
+}
+
+func (c *Config) writeRevision(dir string, r Revision) (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	mkdirall(dir, 0700)
+	f, err := createTemp(dir, ".revision.*")
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%020d.json", r.Time.UnixNano()))
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = rename(f.Name(), name)
+	}
+	if err != nil {
+		remove(f.Name())
+		return "", err
+	}
+	return name, nil
+}
+
+// Record the merged configuration as the newest revision, unless it matches
+// the newest already, and discard those beyond the depth kept.
+func (c *Config) record() {
+	c.mu.RLock()
+	depth, dir := c.depth, c.historyDir
+	c.mu.RUnlock()
+	if depth == 0 {
+		return
+	}
+	f := c.Fingerprint()
+	c.mu.RLock()
+	r := Revision{Time: now(), Fingerprint: f, Values: c.clone(c.merged), Sources: c.copySources(c.sources)}
+	same := len(c.history) > 0 && reflect.DeepEqual(c.history[0].Values, r.Values)
+	c.mu.RUnlock()
+	if same {
+		return
+	}
+	if dir != "" {
+		var err error
+		if r.file, err = c.writeRevision(dir, r); err != nil {
+			c.notify(slog.LevelWarn, "failed to save configuration revision: %v", err)
+		}
+	}
+	c.mu.Lock()
+	c.history = append([]Revision{r}, c.history...)
+	var old []Revision
+	if len(c.history) > depth {
+		c.history, old = c.history[:depth], c.history[depth:]
+	}
+	c.mu.Unlock()
+	for _, o := range old {
+		if o.file != "" {
+			remove(o.file)
+		}
+	}
+}
+
+// Keep the last n configurations that were successfully loaded, reloaded, or
+// applied, so that History can list them and Rollback can restore them.  When
+// a directory is supplied each revision is also written there, readable only
+// by the owner since it contains sensitive values, and the revisions already
+// found there are kept, so that history survives a restart.  Zero disables it.
+func (c *Config) KeepHistory(n int, dir string) error {
+	var found []Revision
+	var errs []error
+	var list []os.FileInfo
+	if dir != "" {
+		list, _ = readdir(dir)
+	}
+	for _, fi := range list {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		name := filepath.Join(dir, fi.Name())
+		var r Revision
+		data, err := readfile(name)
+		if err == nil {
+			err = json.Unmarshal(data, &r)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		r.file = name
+		found = append(found, r)
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Time.After(found[j].Time) })
+	if n < 0 {
+		n = 0
+	}
+	if len(found) > n {
+		for _, o := range found[n:] {
+			remove(o.file)
+		}
+		found = found[:n]
+	}
+	c.mu.Lock()
+	c.depth, c.historyDir, c.history = n, dir, found
+	c.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Return the revisions kept by KeepHistory, newest first, where the first is
+// the configuration in effect and sensitive values are masked.
+func (c *Config) History() []Revision {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]Revision, len(c.history))
+	for i, r := range c.history {
+		r.Values, r.Sources, r.file = c.clone(r.Values), c.copySources(r.Sources), ""
+		c.mask("", r.Values)
+		list[i] = r
+	}
+	return list
+}
+
+// Restore the revision n steps before the configuration in effect, so that an
+// operator may revert a bad live change without editing files.  Keys supplied
+// since the revision are reset to the value the target held before the first
+// Load, and the merged configuration and the source of each key are restored.
+// It triggers any OnReload callbacks, and becomes the newest revision, so that
+// Rollback(1) undoes a rollback.
+func (c *Config) Rollback(n int) error {
+	if err := c.thawed("change"); err != nil {
+		return err
+	}
+	c.mu.RLock()
+	if n < 1 || n >= len(c.history) {
+		c.mu.RUnlock()
+		return errNoRevision
+	}
+	values, sources := c.clone(c.history[n].Values), c.copySources(c.history[n].Sources)
+	current, baseline := c.flatten("", c.merged, map[string]interface{}{}), c.baseline
+	c.mu.RUnlock()
+	if err := c.validate(values); err != nil {
+		return err
+	}
+	kept, zero := c.flatten("", values, map[string]interface{}{}), map[string]interface{}{}
+	for k := range current {
+		if _, ok := kept[k]; !ok {
+			c.set(zero, k, baseline[k])
+		}
+	}
+	before := c.snapshot()
+	err := c.to(c.merge(zero, values))
+	c.flush()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.merged, c.sources = values, sources
+	c.mu.Unlock()
+	c.record()
+	c.changed(c.diff(before, c.snapshot()))
+	return nil
+}
@@ -0,0 +1,89 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	d, e := ioutil.TempDir(os.TempDir(), "gonf")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(d)
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	mkdirall, createTemp, rename, remove, readdir = os.MkdirAll, ioutil.TempFile, os.Rename, os.Remove, ioutil.ReadDir
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(n string) ([]byte, error) {
+		if n == "/tmp/app.json" {
+			return []byte(`{"OptionString": "file", "EnvString": "secret"}`), nil
+		} else if n == "/tmp/other.json" {
+			return []byte(`{"EnvString": "a"}`), nil
+		}
+		return ioutil.ReadFile(n)
+	}
+
+	// test nothing is kept by default
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	if e := c.Load("/tmp/app.json"); e != nil || len(c.History()) != 0 || c.Rollback(1) != errNoRevision {
+		t.Errorf("failed to disable history by default, %v %v...", e, c.History())
+	}
+
+	// test revisions are kept up to the depth with sensitive values masked
+	if e := c.KeepHistory(2, d); e != nil {
+		t.Fatalf("failed to keep history, %v...", e)
+	}
+	c.Load("/tmp/app.json")
+	c.Set("OptionString", "first")
+	c.Set("OptionString", "second")
+	if h := c.History(); len(h) != 2 || h[0].Values["OptionString"] != "second" || h[1].Values["OptionString"] != "first" || h[0].Values["EnvString"] != redacted {
+		t.Errorf("failed to record history, %v...", h)
+	}
+	if list, _ := ioutil.ReadDir(d); len(list) != 2 {
+		t.Errorf("failed to prune revisions on disk, %d...", len(list))
+	}
+
+	// test rolling back restores an earlier revision
+	if e := c.Rollback(1); e != nil || mc.OptionString != "first" || mc.EnvString != "secret" || c.History()[0].Values["OptionString"] != "first" {
+		t.Errorf("failed to roll back, %v %+v...", e, mc)
+	}
+
+	// test rolling back removes keys supplied after the revision
+	c = &Config{}
+	mc = &mockConfig{}
+	c.Target(mc)
+	c.KeepHistory(3, "")
+	c.Load("/tmp/other.json")
+	c.Set("OptionString", "bad")
+	if e := c.Rollback(1); e != nil || mc.OptionString != "" || mc.EnvString != "a" {
+		t.Errorf("failed to remove key supplied after the revision, %v %+v...", e, mc)
+	} else if v, ok := c.Get("OptionString"); ok && v != nil && v != "" {
+		t.Errorf("failed to restore merged configuration, %v...", v)
+	} else if s := c.source("EnvString"); s != "/tmp/other.json" {
+		t.Errorf("failed to restore sources, %s...", s)
+	}
+
+	// test rolling back keeps defaults set on the target before Load
+	c = &Config{}
+	mc = &mockConfig{OptionString: "default", OptionNumber: 7}
+	c.Target(mc)
+	c.KeepHistory(3, "")
+	c.Load("/tmp/other.json")
+	c.Set("OptionNumber", 9)
+	if e := c.Rollback(1); e != nil || mc.OptionString != "default" || mc.OptionNumber != 7 || mc.EnvString != "a" {
+		t.Errorf("failed to keep defaults set on the target, %v %+v...", e, mc)
+	}
+
+	// test history survives a restart
+	c = &Config{}
+	if e := c.KeepHistory(5, d); e != nil || len(c.History()) != 2 || c.History()[0].Values["OptionString"] != "first" {
+		t.Errorf("failed to restore history from disk, %v %v...", e, c.History())
+	}
+}
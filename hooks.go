@@ -0,0 +1,38 @@
+package gonf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Transforms a merged value before it populates a field of the target, given
+// the type of the field and its dot-notation path, such as decoding a base64
+// string into a []byte.  A hook that does not apply returns the value as is.
+type DecodeHook func(v interface{}, t reflect.Type, path string) (interface{}, error)
+
+// Run the DecodeHooks in the order they were added while the lock is held,
+// reporting whether they converted the value to a type that may be assigned
+// to the field directly.
+func (c *Config) hooked(d reflect.Value, v interface{}, path string) (interface{}, bool, error) {
+	t := reflect.TypeOf(v)
+	for _, h := range c.hooks {
+		var err error
+		if v, err = h(v, d.Type(), path); err != nil {
+			return v, false, fmt.Errorf("%w %v (%s)", ErrCast, err, path)
+		}
+	}
+	return v, v != nil && reflect.TypeOf(v) != t && reflect.TypeOf(v).AssignableTo(d.Type()), nil
+}
+
+// Add a hook run for every field, at every depth, and every element of slices
+// and maps, while the target is populated by Load, Reload, and Apply, so that
+// values are transformed without changing the types of the target.  Targets
+// that are an Applier populate themselves, and are not affected.
+func (c *Config) DecodeHook(fn DecodeHook) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	c.hooks = append(c.hooks, fn)
+	c.mu.Unlock()
+}
@@ -0,0 +1,48 @@
+package gonf
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type mockHooked struct {
+	Key   []byte
+	Name  *string
+	Names []string
+}
+
+func TestDecodeHook(t *testing.T) {
+	m := &mockHooked{}
+	c := &Config{}
+	c.Target(m)
+	var paths []string
+	c.DecodeHook(nil)
+	c.DecodeHook(func(v interface{}, t reflect.Type, path string) (interface{}, error) {
+		paths = append(paths, path)
+		if s, ok := v.(string); ok && t == reflect.TypeOf([]byte(nil)) {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		return v, nil
+	})
+	c.DecodeHook(func(v interface{}, t reflect.Type, path string) (interface{}, error) {
+		if s, ok := v.(string); ok && t.Kind() == reflect.String {
+			return strings.ToUpper(s), nil
+		}
+		return v, nil
+	})
+	if e := c.Apply(map[string]interface{}{"Key": "c2VjcmV0", "Name": "name", "Names": []interface{}{"a", "b"}}); e != nil || string(m.Key) != "secret" || m.Name == nil || *m.Name != "NAME" || strings.Join(m.Names, ",") != "A,B" {
+		t.Errorf("failed to run decode hooks, %v %+v...", e, m)
+	}
+	if sort.Strings(paths); strings.Join(paths, ",") != "Key,Name,Names,Names.0,Names.1" {
+		t.Errorf("failed to run hooks once per field and element, %v...", paths)
+	}
+
+	// test errors are reported with the path
+	if e := c.Apply(map[string]interface{}{"Key": "!"}); !errors.Is(e, ErrCast) || !strings.Contains(e.Error(), "(Key)") {
+		t.Errorf("failed to report decode hook error, %v...", e)
+	}
+}
@@ -0,0 +1,42 @@
+package gonf
+
+// Copy a map and every map nested within it.
+func (c *Config) clone(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if n, ok := v.(map[string]interface{}); ok {
+			v = c.clone(n)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Merge a map into an accumulator by mutating it, copying maps the first time
+// they are inserted so the accumulator never shares them with its sources.
+func (c *Config) mergeInto(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		n, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+		} else if m, is := dst[k].(map[string]interface{}); is {
+			c.mergeInto(m, n)
+		} else if l, is := dst[k].([]interface{}); is {
+			dst[k] = c.element(l, n)
+		} else {
+			dst[k] = c.clone(n)
+		}
+	}
+	return dst
+}
+
+// When enabled, the configuration retained for Get is merged in place rather
+// than rebuilt with new maps at every level on each Load, Reload, Apply, and
+// Set, and is applied to the target without first being copied again, for
+// services that reload large configurations many times per minute.  Maps
+// returned by Get may then change with later merges.
+func (c *Config) MergeInPlace(enabled bool) {
+	c.mu.Lock()
+	c.inPlace = enabled
+	c.mu.Unlock()
+}
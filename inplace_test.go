@@ -0,0 +1,61 @@
+package gonf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeInPlace(t *testing.T) {
+	source := map[string]interface{}{"nested": map[string]interface{}{"a": 1.0}}
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.MergeInPlace(true)
+
+	// test the accumulator is reused without sharing source maps
+	c.remember(source)
+	before := reflect.ValueOf(c.merged).Pointer()
+	c.remember(map[string]interface{}{"nested": map[string]interface{}{"b": 2.0}, "top": "x"})
+	if reflect.ValueOf(c.merged).Pointer() != before || len(c.merged["nested"].(map[string]interface{})) != 2 || c.merged["top"] != "x" {
+		t.Errorf("failed to merge in place, %v...", c.merged)
+	}
+	if len(source["nested"].(map[string]interface{})) != 1 {
+		t.Errorf("failed to protect source maps, %v...", source)
+	}
+
+	// test fewer allocations than rebuilding
+	vars := map[string]interface{}{"nested": map[string]interface{}{"c": 3.0}}
+	inPlace := testing.AllocsPerRun(100, func() { c.remember(vars) })
+	c.MergeInPlace(false)
+	rebuilt := testing.AllocsPerRun(100, func() { c.remember(vars) })
+	if inPlace >= rebuilt {
+		t.Errorf("failed to reduce allocations, %v >= %v...", inPlace, rebuilt)
+	}
+}
+
+func TestMergeInPlaceReload(t *testing.T) {
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	var modified int64
+	stat = func(string) (os.FileInfo, error) { modified++; return &mockStat{modTime: time.Unix(modified, 0)}, nil }
+	var fields []string
+	for i := 0; i < 50; i++ {
+		fields = append(fields, fmt.Sprintf(`"group%d": {"key": %d}`, i, i))
+	}
+	readfile = func(string) ([]byte, error) { return []byte(`{` + strings.Join(fields, ",") + `}`), nil }
+	reload := func(enabled bool) float64 {
+		c := &Config{}
+		c.Target(&mockConfig{})
+		c.MergeInPlace(enabled)
+		c.Load("app.json")
+		return testing.AllocsPerRun(20, func() { c.Reload() })
+	}
+
+	// test reloads allocate less when merging in place
+	if inPlace, rebuilt := reload(true), reload(false); inPlace >= rebuilt {
+		t.Errorf("failed to reduce allocations on reload, %v >= %v...", inPlace, rebuilt)
+	}
+}
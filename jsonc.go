@@ -0,0 +1,155 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Strip `//`, `/* */`, and `#` comments outside of quoted strings, where a
+// backslash escapes the character after it, keeping the newline that ends a
+// line comment.  A string or block comment left unterminated runs to the end.
+func (c *Config) comment(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch b := data[i]; {
+		case b == '"' || b == '\'':
+			start := i
+			for i++; i < len(data) && data[i] != b; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				i = len(data) - 1
+			}
+			out = append(out, data[start:i+1]...)
+		case b == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case b == '#' || (b == '/' && i+1 < len(data) && data[i+1] == '/'):
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				return out
+			}
+			i += end - 1
+		default:
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+type frame struct {
+	path   string
+	key    string
+	object bool
+}
+
+func (c *Config) scanJSON(data []byte, onKey func(path string, line int), onComment func(text string)) {
+	stack := []frame{{}}
+	for i := 0; i < len(data); i++ {
+		switch b := data[i]; {
+		case b == '"' || b == '\'':
+			start := i
+			for i++; i < len(data) && data[i] != b; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			j := i + 1
+			for j < len(data) && strings.IndexByte(" \t\r\n", data[j]) >= 0 {
+				j++
+			}
+			if top := &stack[len(stack)-1]; top.object && j < len(data) && data[j] == ':' && i < len(data) {
+				var key string
+				if json.Unmarshal(data[start:i+1], &key) != nil {
+					key = string(data[start+1 : i])
+				}
+				top.key = key
+				path := key
+				if top.path != "" {
+					path = top.path + "." + key
+				}
+				onKey(path, bytes.LastIndexByte(data[:start], '\n')+1)
+			}
+		case b == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(data) - i - 4
+			}
+			onComment(string(data[i : i+end+4]))
+			i += end + 3
+		case b == '/' && i+1 < len(data) && data[i+1] == '/':
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data) - i
+			}
+			onComment(strings.TrimRight(string(data[i:i+end]), "\r"))
+			i += end - 1
+		case b == '{' || b == '[':
+			top := stack[len(stack)-1]
+			path := top.path
+			if top.object {
+				if path != "" {
+					path += "."
+				}
+				path += top.key
+			}
+			stack = append(stack, frame{path: path, object: b == '{'})
+		case (b == '}' || b == ']') && len(stack) > 1:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func (c *Config) extractComments(data []byte) map[string][]string {
+	var pending []string
+	comments := map[string][]string{}
+	c.scanJSON(data, func(path string, _ int) {
+		if len(pending) > 0 {
+			if _, ok := comments[path]; !ok {
+				comments[path], pending = pending, nil
+			}
+		}
+	}, func(text string) {
+		pending = append(pending, text)
+	})
+	if len(pending) > 0 {
+		comments[""] = pending
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	return comments
+}
+
+func (c *Config) insertComments(data []byte, comments map[string][]string) []byte {
+	if len(comments) == 0 {
+		return data
+	}
+	out := &bytes.Buffer{}
+	last := 0
+	used := map[string]struct{}{}
+	c.scanJSON(data, func(path string, line int) {
+		if _, ok := used[path]; ok || len(comments[path]) == 0 {
+			return
+		}
+		used[path] = struct{}{}
+		indent := data[line : line+len(data[line:])-len(bytes.TrimLeft(data[line:], " \t"))]
+		out.Write(data[last:line])
+		for _, t := range comments[path] {
+			out.Write(indent)
+			out.WriteString(t + "\n")
+		}
+		last = line
+	}, func(string) {})
+	out.Write(data[last:])
+	for _, t := range comments[""] {
+		out.WriteString(t + "\n")
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,98 @@
+package gonf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	data := []byte(`{
+	// the first comment
+	"OptionString": "a // not a comment",
+	/* explains
+	   the composite */
+	"ExplicitComposite": {
+		// nested comment
+		"DepthByOption": 1
+	},
+	"OptionSlice": ["/* not a comment */"]
+	// trailing comment
+}`)
+	comments := map[string][]string{}
+	c := &Config{}
+	if comments = c.extractComments(data); len(comments) != 4 ||
+		comments["OptionString"][0] != "// the first comment" ||
+		comments["ExplicitComposite"][0] != "/* explains\n\t   the composite */" ||
+		comments["ExplicitComposite.DepthByOption"][0] != "// nested comment" || comments[""][0] != "// trailing comment" {
+		t.Errorf("failed to extract comments, %#v...", comments)
+	}
+	if c.extractComments([]byte(`{"a": "b"}`)) != nil {
+		t.Error("failed to ignore json without comments...")
+	}
+
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return data, nil }
+	mc := &mockConfig{}
+	c.Target(mc)
+	c.configFile = "/tmp/test.json"
+	if _, e := c.readFile(context.Background()); e != nil {
+		t.Fatalf("failed to read file with comments, %v...", e)
+	}
+	out, e := c.encode(c.configFile)
+	if e != nil {
+		t.Fatalf("failed to encode with comments, %v...", e)
+	}
+	if !strings.Contains(string(out), "\t// the first comment\n\t\"OptionString\"") ||
+		!strings.Contains(string(out), "\t\t// nested comment\n\t\t\"DepthByOption\"") ||
+		!strings.HasSuffix(string(out), "}\n// trailing comment\n") {
+		t.Errorf("failed to restore comments, %s...", out)
+	}
+	if _, e := c.decode(c.configFile, out); e != nil {
+		t.Errorf("failed to parse restored comments, %v...", e)
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(c.comment(out), &m) != nil || m["OptionString"] != "" {
+		t.Error("failed to produce valid json after filtering comments...")
+	}
+}
+
+func TestComment(t *testing.T) {
+	c := &Config{}
+	for in, out := range map[string]string{
+		`{"a": "b"}`:                       `{"a": "b"}`,
+		`{"a": "\" // not a comment"}`:     `{"a": "\" // not a comment"}`,
+		`{"a": "\\"} // comment`:           `{"a": "\\"} `,
+		`{"a": "/* \"x\" */"} # comment`:   `{"a": "/* \"x\" */"} `,
+		"{\"a\": 1, // comment\n\"b\": 2}": "{\"a\": 1, \n\"b\": 2}",
+		`{"a": /* "quoted" */ 1}`:          `{"a":  1}`,
+		`{"a": 1} /* unterminated`:         `{"a": 1} `,
+		`{"a": "unterminated \`:            `{"a": "unterminated \`,
+		`{"a": 'single // quoted'}`:        `{"a": 'single // quoted'}`,
+	} {
+		if got := string(c.comment([]byte(in))); got != out {
+			t.Errorf("failed to strip comments from %s, got %s...", in, got)
+		}
+	}
+}
+
+func FuzzComment(f *testing.F) {
+	for _, s := range []string{`{"a": "b"}`, `{"a": "\" // x"}`, `{"a": "\\", "b": "/* c */"}`, `["#", "'", """]`} {
+		f.Add([]byte(s))
+	}
+	c := &Config{}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out := c.comment(data)
+		if !json.Valid(data) {
+			return
+		}
+		var want, got interface{}
+		json.Unmarshal(data, &want)
+		if err := json.Unmarshal(out, &got); err != nil || !reflect.DeepEqual(want, got) {
+			t.Errorf("failed to preserve json without comments, %s became %s...", data, out)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package gonf
+
+import "strings"
+
+// Rename every key of a source at each level with the KeyMapper, dropping
+// those mapped to an empty string.
+func (c *Config) mapKeys(vars map[string]interface{}) map[string]interface{} {
+	c.mu.RLock()
+	fn := c.keyMapper
+	c.mu.RUnlock()
+	if fn == nil || vars == nil {
+		return vars
+	}
+	return c.rename(fn, vars)
+}
+
+func (c *Config) rename(fn func(string) string, vars map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		if k = fn(k); k == "" {
+			continue
+		} else if m, ok := v.(map[string]interface{}); ok {
+			v = c.rename(fn, m)
+		}
+		if m, ok := out[k].(map[string]interface{}); ok {
+			if n, is := v.(map[string]interface{}); is {
+				v = c.merge(m, n)
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Rename the flattened keys of recorded origins with the mapper, so that
+// provenance is reported under the keys that are actually applied.
+func (c *Config) renameOrigins(fn func(string) string, origins map[string]string) map[string]string {
+	if fn == nil || origins == nil {
+		return origins
+	}
+	out := make(map[string]string, len(origins))
+	for k, v := range origins {
+		parts, dropped := strings.Split(k, "."), false
+		for i := range parts {
+			if parts[i] = fn(parts[i]); parts[i] == "" {
+				dropped = true
+				break
+			}
+		}
+		if !dropped {
+			out[strings.Join(parts, ".")] = v
+		}
+	}
+	return out
+}
+
+// Supply a function applied to each key, at every level, of the defaults,
+// files, environment variables, command line options, and overrides before
+// they are merged, so that house conventions such as stripping a prefix or
+// translating legacy names are implemented in one place.  A key mapped to an
+// empty string is dropped, and nil removes the mapper.
+func (c *Config) KeyMapper(fn func(string) string) {
+	c.mu.Lock()
+	c.keyMapper = fn
+	c.mu.Unlock()
+}
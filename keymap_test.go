@@ -0,0 +1,55 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestKeyMapper(t *testing.T) {
+	os.Args = []string{"app", "--legacy=option"}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{}, nil }
+	readfile = func(string) ([]byte, error) {
+		return []byte(`{"app_EnvString": "file", "app_ExplicitComposite": {"app_TripleDepth": "deep"}, "comment": "dropped"}`), nil
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Strict(true)
+	c.Add("legacyOption", "", "", "--legacy")
+	c.KeyMapper(func(k string) string {
+		if k == "comment" {
+			return ""
+		} else if k == "legacyOption" {
+			return "OptionString"
+		}
+		return strings.TrimPrefix(k, "app_")
+	})
+	if e := c.Load("/tmp/app.json"); e != nil || mc.EnvString != "file" || mc.ExplicitComposite.TripleDepth != "deep" || mc.OptionString != "option" {
+		t.Errorf("failed to map keys of every source, %v %+v...", e, mc)
+	}
+	if s := c.source("EnvString"); s != "/tmp/app.json" {
+		t.Errorf("failed to record the source of mapped keys, %s...", s)
+	}
+	if s := c.source("OptionString"); !strings.HasPrefix(s, "option") {
+		t.Errorf("failed to record the source of mapped options, %s...", s)
+	}
+	if e := c.Apply(map[string]interface{}{"app_OptionNumber": 2}); e != nil || mc.OptionNumber != 2 {
+		t.Errorf("failed to map keys of overrides, %v %v...", e, mc.OptionNumber)
+	}
+
+	// test origins are dropped when any segment is dropped
+	origins := c.renameOrigins(func(k string) string {
+		if k == "comment" {
+			return ""
+		}
+		return k
+	}, map[string]string{"a.comment.c": "/tmp/app.json", "a.b": "/tmp/app.json"})
+	if len(origins) != 1 || origins["a.b"] == "" {
+		t.Errorf("failed to drop origins of dropped keys, %v...", origins)
+	}
+}
@@ -0,0 +1,46 @@
+package gonf
+
+import "sync"
+
+type rlocker interface {
+	RLock()
+	RUnlock()
+}
+
+func (c *Config) guard(v interface{}) interface{} {
+	if c.locker != nil {
+		return c.locker
+	}
+	return v
+}
+
+// Lock the target for writing while the lock is held, returning the function
+// that releases it.
+func (c *Config) writeLock(v interface{}) func() {
+	if l, ok := c.guard(v).(locker); ok {
+		l.Lock()
+		return l.Unlock
+	}
+	return func() {}
+}
+
+// Lock the target for reading while the lock is held, using a read lock when
+// it is supported, returning the function that releases it.
+func (c *Config) readLock(v interface{}) func() {
+	if l, ok := c.guard(v).(rlocker); ok {
+		l.RLock()
+		return l.RUnlock
+	}
+	return c.writeLock(v)
+}
+
+// Supply the Locker used while applying configuration to the target, in place
+// of any Lock and Unlock functions on the target, for applications with their
+// own synchronization.  If it also supplies RLock and RUnlock, as does
+// sync.RWMutex, reading the target only takes the read lock.  A nil Locker
+// restores detection on the target.
+func (c *Config) Locker(l sync.Locker) {
+	c.mu.Lock()
+	c.locker = l
+	c.mu.Unlock()
+}
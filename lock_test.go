@@ -0,0 +1,49 @@
+package gonf
+
+import (
+	"sync"
+	"testing"
+)
+
+type mockLocker struct {
+	locks, rlocks int
+}
+
+func (l *mockLocker) Lock()    { l.locks++ }
+func (l *mockLocker) Unlock()  {}
+func (l *mockLocker) RLock()   { l.rlocks++ }
+func (l *mockLocker) RUnlock() {}
+
+type mockRWConfig struct {
+	mockLocker
+	Name string
+}
+
+func TestLocker(t *testing.T) {
+	mc := &mockRWConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.to(map[string]interface{}{"Name": "value"})
+	c.snapshot()
+	if mc.Name != "value" || mc.locks != 1 || mc.rlocks != 1 {
+		t.Errorf("failed to use read and write locks of the target, %+v...", mc)
+	}
+
+	// test injected locker takes the place of the target
+	ml := &mockLocker{}
+	c.Locker(ml)
+	c.to(map[string]interface{}{"Name": "other"})
+	c.snapshot()
+	if ml.locks != 1 || ml.rlocks != 1 || mc.locks != 1 || mc.rlocks != 1 {
+		t.Errorf("failed to use injected locker, %+v %+v...", ml, mc)
+	}
+
+	// test write locks are used to read without read locks
+	c.Locker(&sync.Mutex{})
+	c.snapshot()
+	c.Locker(nil)
+	c.snapshot()
+	if mc.rlocks != 2 {
+		t.Error("failed to restore target locking...")
+	}
+}
@@ -0,0 +1,54 @@
+package gonf
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type warner interface {
+	Warn(string, ...interface{})
+}
+
+type failer interface {
+	Error(string, ...interface{})
+}
+
+func (c *Config) notify(level slog.Level, format string, args ...interface{}) {
+	c.mu.RLock()
+	t, s := c.target, c.slog
+	c.mu.RUnlock()
+	msg := fmt.Sprintf(format, args...)
+	if s != nil {
+		s.Log(context.Background(), level, msg)
+		return
+	}
+	if l, ok := t.(failer); ok && level >= slog.LevelError {
+		l.Error("%s", msg)
+	} else if l, ok := t.(warner); ok && level >= slog.LevelWarn {
+		l.Warn("%s", msg)
+	} else if l, ok := t.(logger); ok && level >= slog.LevelInfo {
+		l.Info("%s", msg)
+	} else if l, ok := t.(logger); ok {
+		l.Debug("%s", msg)
+	}
+}
+
+// Record a recoverable problem while the lock is held, to be logged once it
+// has been released.
+func (c *Config) warning(format string, args ...interface{}) {
+	c.pending = append(c.pending, fmt.Sprintf(format, args...))
+}
+
+func (c *Config) flush() {
+	c.mu.Lock()
+	pending, traces := c.pending, c.traces
+	c.pending, c.traces = nil, nil
+	c.mu.Unlock()
+	for _, p := range pending {
+		c.notify(slog.LevelWarn, "%s", p)
+	}
+	for _, t := range traces {
+		c.trace("%s", t)
+	}
+}
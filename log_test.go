@@ -0,0 +1,49 @@
+package gonf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockLevels struct {
+	mockLogger
+	OptionNumber int
+	Labels       map[string]string
+	warnings     []string
+	errors       []string
+}
+
+func (l *mockLevels) Warn(f string, a ...interface{})  { l.warnings = append(l.warnings, a[0].(string)) }
+func (l *mockLevels) Error(f string, a ...interface{}) { l.errors = append(l.errors, a[0].(string)) }
+
+func TestLevels(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	data := `{"OptionNumber": 1, "optionnumber": 2, "Labels": {"a": "b"}, "Unknown": {"Nested": true}}`
+	stat = func(string) (os.FileInfo, error) { return nil, mockError }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+	os.Setenv("APP_NUMBER", "abc")
+
+	ml := &mockLevels{}
+	c := &Config{}
+	c.Target(ml)
+	c.Add("OptionNumber", "", "APP_NUMBER")
+	c.Load("/tmp/app.json")
+	warnings := strings.Join(ml.warnings, "\n")
+	for _, w := range []string{"unknown configuration key Unknown.Nested", `unable to convert "abc" to int`, "unable to stat /tmp/app.json"} {
+		if !strings.Contains(warnings, w) {
+			t.Errorf("failed to warn %s, %s...", w, warnings)
+		}
+	}
+	if strings.Contains(warnings, "optionnumber") || strings.Contains(warnings, "Labels") {
+		t.Errorf("failed to recognize known keys, %s...", warnings)
+	}
+
+	// test reload failures are errors
+	data = `not json`
+	if c.Reload() == nil || len(ml.errors) != 1 || ml.infos != 0 {
+		t.Errorf("failed to log reload failure as an error, %v %d...", ml.errors, ml.infos)
+	}
+}
@@ -0,0 +1,44 @@
+package gonf
+
+import "strings"
+
+// Return the labeled application metadata that has been set, in the order it
+// is printed, while the lock is held.
+func (c *Config) metadata() [][2]string {
+	var list [][2]string
+	for _, m := range [][2]string{
+		{"Version", c.version},
+		{"Authors", strings.Join(c.authors, ", ")},
+		{"Homepage", c.homepage},
+		{"License", c.license},
+	} {
+		if m[1] != "" {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// Identify the authors of the application in help, version, and man page
+// output.
+func (c *Config) Authors(names ...string) {
+	c.mu.Lock()
+	c.authors = append(c.authors[:0:0], names...)
+	c.mu.Unlock()
+}
+
+// Identify the homepage of the application in help, version, and man page
+// output.
+func (c *Config) Homepage(url string) {
+	c.mu.Lock()
+	c.homepage = url
+	c.mu.Unlock()
+}
+
+// Identify the license of the application in help, version, and man page
+// output.
+func (c *Config) License(name string) {
+	c.mu.Lock()
+	c.license = name
+	c.mu.Unlock()
+}
@@ -0,0 +1,47 @@
+package gonf
+
+import (
+	"bytes"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	defer func() { readBuildInfo = debug.ReadBuildInfo }()
+
+	out := &bytes.Buffer{}
+	c := &Config{}
+	c.Output(out)
+	c.Description("testing metadata")
+	c.Version("1.2.3")
+	c.Authors("Alice", "Bob")
+	c.Homepage("https://example.com")
+	c.License("MIT")
+
+	// test help identifies the application
+	if c.Help(); !strings.Contains(out.String(), "\tVersion: 1.2.3\n\tAuthors: Alice, Bob\n\tHomepage: https://example.com\n\tLicense: MIT\n") {
+		t.Errorf("failed to print metadata in help, %s...", out.String())
+	}
+
+	// test version output
+	out.Reset()
+	if c.printVersion(); out.String() != appName+" 1.2.3\n\tAuthors: Alice, Bob\n\tHomepage: https://example.com\n\tLicense: MIT\n" {
+		t.Errorf("failed to print metadata with version, %q...", out.String())
+	}
+
+	// test man page sections
+	m := c.Man()
+	for _, s := range []string{`" "` + c.roff(appName) + " 1.2.3\"\n", ".SH AUTHORS\nAlice, Bob\n", ".SH COPYRIGHT\nMIT\n", ".SH SEE ALSO\nhttps://example.com\n"} {
+		if !strings.Contains(m, s) {
+			t.Errorf("failed to generate man page with %q, %s...", s, m)
+		}
+	}
+
+	// test unset metadata is omitted
+	c = &Config{}
+	if len(c.metadata()) != 0 {
+		t.Error("failed to omit unset metadata...")
+	}
+}
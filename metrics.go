@@ -0,0 +1,81 @@
+package gonf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"time"
+)
+
+var now = time.Now
+
+// Receives the outcome of every Load and Reload that was attempted, so that
+// monitoring can alert when a host fails to pick up a configuration push.
+type Metrics interface {
+	Loaded(reload bool, fingerprint string, err error)
+}
+
+type status struct {
+	Reloads     int64     `json:"reloads"`
+	LastReload  time.Time `json:"lastReload"`
+	LastError   string    `json:"lastError"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+// Return a stable hash of the effective configuration, where sensitive values
+// are masked so that neither they nor changes to them are exposed, which fleet
+// tooling may compare to detect hosts running divergent configuration.  It is
+// also supplied to Metrics and published by Publish.
+func (c *Config) Fingerprint() string {
+	values := c.snapshot()
+	for k := range values {
+		if c.isSensitive(k) {
+			values[k] = redacted
+		}
+	}
+	data, _ := json.Marshal(values)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Config) loaded(reload bool, err error) {
+	if err == errNoChanges {
+		return
+	} else if err == nil {
+		c.record()
+	}
+	f := c.Fingerprint()
+	c.mu.Lock()
+	if reload {
+		c.status.Reloads++
+		c.status.LastReload = now()
+	}
+	c.status.LastError, c.status.Fingerprint = "", f
+	if err != nil {
+		c.status.LastError = err.Error()
+	}
+	m := c.metrics
+	c.mu.Unlock()
+	if m != nil {
+		m.Loaded(reload, f, err)
+	}
+}
+
+// Supply Metrics to be notified after every Load and Reload.
+func (c *Config) Metrics(m Metrics) {
+	c.mu.Lock()
+	c.metrics = m
+	c.mu.Unlock()
+}
+
+// Publish the reload count, last reload time, last error, and a fingerprint
+// of the current configuration through expvar under the supplied name.  Like
+// expvar.Publish this panics if the name is already in use.
+func (c *Config) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return c.status
+	}))
+}
@@ -0,0 +1,94 @@
+package gonf
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Expvar names may only be published once per process, so each run of a test
+// publishes under its own name.
+var published int64
+
+type mockMetrics struct {
+	reloads     int
+	fingerprint string
+	err         error
+}
+
+func (m *mockMetrics) Loaded(reload bool, fingerprint string, err error) {
+	if reload {
+		m.reloads++
+	}
+	m.fingerprint, m.err = fingerprint, err
+}
+
+func TestMetrics(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+	modTime := time.Now()
+	data := `{"OptionString": "first"}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	mm := &mockMetrics{}
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Metrics(mm)
+	name := fmt.Sprintf("%s-%d", t.Name(), atomic.AddInt64(&published, 1))
+	c.Publish(name)
+	c.Load("/tmp/app.json")
+	first := mm.fingerprint
+	if first == "" || mm.reloads != 0 || mm.err != nil {
+		t.Errorf("failed to report load, %+v...", mm)
+	}
+
+	// test unchanged reloads are not counted
+	c.Reload()
+	if mm.reloads != 0 {
+		t.Error("failed to ignore unchanged reload...")
+	}
+
+	// test changed and failed reloads
+	modTime, data = modTime.Add(time.Second), `{"OptionString": "second"}`
+	c.Reload()
+	if mm.reloads != 1 || mm.fingerprint == first || mm.err != nil {
+		t.Errorf("failed to report reload, %+v...", mm)
+	}
+	modTime, data = modTime.Add(time.Second), `not json`
+	c.Reload()
+	var s status
+	if json.Unmarshal([]byte(expvar.Get(name).String()), &s) != nil || s.Reloads != 2 || !s.LastReload.Equal(at) || s.LastError == "" || s.Fingerprint != mm.fingerprint {
+		t.Errorf("failed to publish status, %+v...", s)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	mc := &mockConfig{OptionString: "a", EnvString: "secret"}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	first := c.Fingerprint()
+	if len(first) != 64 || c.Fingerprint() != first {
+		t.Errorf("failed to compute a stable fingerprint, %s...", first)
+	}
+
+	// test sensitive values are excluded
+	mc.EnvString = "rotated"
+	if c.Fingerprint() != first {
+		t.Error("failed to exclude sensitive values...")
+	}
+
+	// test other values change the fingerprint
+	mc.OptionString = "b"
+	if c.Fingerprint() == first {
+		t.Error("failed to detect divergent configuration...")
+	}
+}
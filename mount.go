@@ -0,0 +1,108 @@
+package gonf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// A library configuration mounted beneath a namespace of its parent.
+type mount struct {
+	namespace string
+	child     *Config
+}
+
+// Import the settings, defaults, requirements, and sensitive names of every
+// mounted configuration beneath its namespace, and bind its target.
+func (c *Config) mounted() (map[string]interface{}, error) {
+	c.mu.RLock()
+	mounts := append([]mount(nil), c.mounts...)
+	c.mu.RUnlock()
+	defaults := map[string]interface{}{}
+	var errs []error
+	for _, m := range mounts {
+		d, err := m.child.harvest()
+		errs = append(errs, err)
+		if len(d) > 0 {
+			c.set(defaults, m.namespace, d)
+		}
+		m.child.mu.RLock()
+		target, settings := m.child.target, append([]setting(nil), m.child.settings...)
+		var required, sensitive []string
+		for n := range m.child.required {
+			required = append(required, m.namespace+"."+n)
+		}
+		for n := range m.child.sensitive {
+			sensitive = append(sensitive, m.namespace+"."+n)
+		}
+		for _, n := range m.child.tagged(reflect.TypeOf(target), "", "sensitive") {
+			sensitive = append(sensitive, m.namespace+"."+n)
+		}
+		m.child.mu.RUnlock()
+		if target != nil {
+			errs = append(errs, c.Bind(m.namespace, target))
+		}
+		c.Sensitive(sensitive...)
+		c.mu.Lock()
+		for _, s := range settings {
+			s.Name = m.namespace + "." + s.Name
+			if s.Group == "" {
+				s.Group = m.namespace
+			}
+			if !c.imported(s) {
+				c.settings = append(c.settings, s)
+			}
+			if !c.grouped(s.Group) {
+				c.groups = append(c.groups, s.Group)
+			}
+		}
+		if c.required == nil && len(required) > 0 {
+			c.required = map[string]struct{}{}
+		}
+		for _, n := range required {
+			c.required[n] = struct{}{}
+		}
+		c.mu.Unlock()
+	}
+	return defaults, errors.Join(errs...)
+}
+
+func (c *Config) imported(s setting) bool {
+	for _, e := range c.settings {
+		if reflect.DeepEqual(e, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) grouped(group string) bool {
+	for _, g := range c.groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Mount the configuration exported by a library beneath a namespace, so its
+// settings, struct tag defaults, requirements, and choices are applied to its
+// target by a single Load of the parent, and listed in the parent help under
+// a group named after the namespace unless they already belong to one.
+func (c *Config) Mount(namespace string, child *Config) error {
+	if child == nil || child == c {
+		return errNilTarget
+	} else if namespace == "" || strings.HasPrefix(namespace, ".") || strings.HasSuffix(namespace, ".") || strings.Contains(namespace, "..") {
+		return errBadNameSyntax
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, m := range c.mounts {
+		if m.namespace == namespace {
+			c.mounts[i].child = child
+			return nil
+		}
+	}
+	c.mounts = append(c.mounts, mount{namespace, child})
+	return nil
+}
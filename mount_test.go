@@ -0,0 +1,61 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockLibrary struct {
+	Driver   string `json:"driver" gonf:"default=sqlite"`
+	Password string `json:"password" gonf:"env=DB_PASSWORD,sensitive,required"`
+	Pool     int    `json:"pool"`
+}
+
+func TestMount(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(`{"db": {"pool": 4}}`), nil }
+	var out []string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) { out = append(out, fmt.Sprintf(f, a...)); return 0, nil }
+	exit = func(int) {}
+
+	ml := &mockLibrary{}
+	lib := &Config{}
+	lib.Target(ml)
+	lib.Add("driver", "database driver", "", "--db-driver")
+	lib.Choices("driver", "sqlite", "postgres")
+
+	c := &Config{}
+	if c.Mount("db", nil) == nil || c.Mount("db..x", lib) == nil {
+		t.Error("failed to reject bad mounts...")
+	}
+	c.Description("test")
+	c.Mount("db", lib)
+
+	// test defaults, requirements, and file values
+	os.Args = []string{"app"}
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, ErrRequired) || ml.Driver != "sqlite" || ml.Pool != 4 {
+		t.Errorf("failed to apply mounted defaults and requirements, %v %+v...", e, ml)
+	}
+
+	// test options, environment variables, choices, and sensitivity
+	os.Setenv("DB_PASSWORD", "secret")
+	os.Args = []string{"app", "--db-driver", "mysql"}
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, ErrInvalidChoice) || ml.Password != "secret" || ml.Driver != "sqlite" || !c.isSensitive("db.password") {
+		t.Errorf("failed to apply mounted settings, %v %+v...", e, ml)
+	}
+	os.Args = []string{"app", "--db-driver", "postgres"}
+	if e := c.Load("/tmp/app.json"); e != nil || ml.Driver != "postgres" || len(c.settings) != 2 {
+		t.Errorf("failed to reload mounted settings, %v %+v %d...", e, ml, len(c.settings))
+	}
+
+	// test unified help
+	c.Help()
+	if h := strings.Join(out, ""); !strings.Contains(h, "\ndb:\n") {
+		t.Errorf("failed to group mounted settings in help, %s...", h)
+	}
+}
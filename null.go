@@ -0,0 +1,17 @@
+package gonf
+
+func (c *Config) empty(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+// When enabled, an environment variable that is set but empty clears the
+// setting it is bound to, like a null in a configuration file, instead of
+// being ignored.
+func (c *Config) ClearOnEmpty(enabled bool) {
+	c.mu.Lock()
+	c.clearEmpty = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,53 @@
+package gonf
+
+import (
+	"os"
+	"testing"
+)
+
+type mockNullEmbed struct {
+	Level int
+}
+
+type mockNull struct {
+	mockNullEmbed
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+	Enabled bool   `json:"enabled"`
+	Nested  struct {
+		Value float64
+	}
+}
+
+func TestNull(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	mn := &mockNull{Name: "default", Port: 80, Enabled: true}
+	mn.Level, mn.Nested.Value = 3, 1.5
+	c := &Config{}
+	c.Target(mn)
+	c.Add("name", "", "APP_NAME")
+	c.Add("port", "", "APP_PORT")
+
+	// test null clears fields while bad values do not
+	if e := c.to(map[string]interface{}{"port": nil, "Level": nil, "Nested": map[string]interface{}{"Value": nil}}, map[string]interface{}{"enabled": "nope"}); e == nil || mn.Port != 0 || mn.Level != 0 || mn.Nested.Value != 0 || !mn.Enabled || mn.Name != "default" {
+		t.Errorf("failed to clear null fields, %v %+v...", e, mn)
+	}
+
+	// test higher precedence null
+	if e := c.to(map[string]interface{}{"name": "file"}, map[string]interface{}{"name": nil}); e != nil || mn.Name != "" {
+		t.Errorf("failed to clear with higher precedence null, %v %+v...", e, mn)
+	}
+
+	// test empty environment variables are ignored unless enabled
+	mn.Name, mn.Port = "default", 80
+	os.Setenv("APP_NAME", "")
+	if e := c.to(c.parseEnvs()); e != nil || mn.Name != "default" {
+		t.Error("failed to ignore empty environment variable...")
+	}
+	c.ClearOnEmpty(true)
+	if e := c.to(c.parseEnvs()); e != nil || mn.Name != "" || mn.Port != 80 {
+		t.Errorf("failed to clear with empty environment variable, %v %+v...", e, mn)
+	}
+}
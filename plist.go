@@ -0,0 +1,344 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var errBadPlist = errors.New("invalid property list...")
+
+// The reference date for binary property list dates.
+var plistEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (c *Config) plist(data []byte) (map[string]interface{}, error) {
+	var v interface{}
+	var err error
+	if bytes.HasPrefix(data, []byte("bplist00")) {
+		v, err = c.binaryPlist(data)
+	} else {
+		v, err = c.xmlPlist(xml.NewDecoder(bytes.NewReader(data)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.rooted(v)
+}
+
+func (c *Config) xmlPlist(d *xml.Decoder) (interface{}, error) {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil, errBadPlist
+		} else if err != nil {
+			return nil, err
+		}
+		if s, ok := t.(xml.StartElement); ok && s.Name.Local != "plist" {
+			return c.xmlPlistValue(d, s)
+		}
+	}
+}
+
+func (c *Config) xmlPlistValue(d *xml.Decoder, s xml.StartElement) (interface{}, error) {
+	switch s.Name.Local {
+	case "dict":
+		m := map[string]interface{}{}
+		var key *string
+		for {
+			t, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch e := t.(type) {
+			case xml.EndElement:
+				return m, nil
+			case xml.StartElement:
+				if e.Name.Local == "key" {
+					var k string
+					if err := d.DecodeElement(&k, &e); err != nil {
+						return nil, err
+					}
+					key = &k
+					continue
+				} else if key == nil {
+					return nil, errBadPlist
+				}
+				v, err := c.xmlPlistValue(d, e)
+				if err != nil {
+					return nil, err
+				}
+				m[*key], key = v, nil
+			}
+		}
+	case "array":
+		l := []interface{}{}
+		for {
+			t, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch e := t.(type) {
+			case xml.EndElement:
+				return l, nil
+			case xml.StartElement:
+				v, err := c.xmlPlistValue(d, e)
+				if err != nil {
+					return nil, err
+				}
+				l = append(l, v)
+			}
+		}
+	case "true", "false":
+		return s.Name.Local == "true", d.Skip()
+	}
+	var text string
+	if err := d.DecodeElement(&text, &s); err != nil {
+		return nil, err
+	}
+	switch s.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "date":
+		return time.Parse(time.RFC3339, strings.TrimSpace(text))
+	case "data":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	}
+	return nil, errBadPlist
+}
+
+type bplist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+	depth   int
+}
+
+func (c *Config) binaryPlist(data []byte) (interface{}, error) {
+	if len(data) < 40 {
+		return nil, errBadPlist
+	}
+	t := data[len(data)-32:]
+	offsetSize, refSize := int(t[6]), int(t[7])
+	count, top, table := binary.BigEndian.Uint64(t[8:]), binary.BigEndian.Uint64(t[16:]), binary.BigEndian.Uint64(t[24:])
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 || top >= count || count > uint64(len(data)) || table > uint64(len(data)) || table+count*uint64(offsetSize) > uint64(len(data)-32) {
+		return nil, errBadPlist
+	}
+	b := &bplist{data: data, refSize: refSize}
+	for i := uint64(0); i < count; i++ {
+		o := table + i*uint64(offsetSize)
+		b.offsets = append(b.offsets, b.uint(data[o:o+uint64(offsetSize)]))
+	}
+	return b.object(top)
+}
+
+func (b *bplist) uint(p []byte) uint64 {
+	var v uint64
+	for _, c := range p {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func (b *bplist) slice(o, n uint64) ([]byte, error) {
+	if o+n < o || o+n > uint64(len(b.data)) {
+		return nil, errBadPlist
+	}
+	return b.data[o : o+n], nil
+}
+
+func (b *bplist) length(o uint64) (uint64, uint64, error) {
+	n := uint64(b.data[o] & 0x0f)
+	if n != 0x0f {
+		return n, o + 1, nil
+	}
+	if o+1 >= uint64(len(b.data)) || b.data[o+1]&0xf0 != 0x10 {
+		return 0, 0, errBadPlist
+	}
+	size := uint64(1) << (b.data[o+1] & 0x0f)
+	p, err := b.slice(o+2, size)
+	if err != nil {
+		return 0, 0, err
+	} else if n = b.uint(p); n > uint64(len(b.data)) {
+		return 0, 0, errBadPlist
+	}
+	return n, o + 2 + size, nil
+}
+
+func (b *bplist) object(ref uint64) (interface{}, error) {
+	if b.depth++; ref >= uint64(len(b.offsets)) || b.depth > 512 {
+		return nil, errBadPlist
+	}
+	defer func() { b.depth-- }()
+	o := b.offsets[ref]
+	if o >= uint64(len(b.data)) {
+		return nil, errBadPlist
+	}
+	marker := b.data[o]
+	switch marker >> 4 {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+		return nil, nil
+	case 0x1:
+		p, err := b.slice(o+1, 1<<(marker&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		return int64(b.uint(p)), nil
+	case 0x2, 0x3:
+		p, err := b.slice(o+1, 1<<(marker&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		var f float64
+		switch len(p) {
+		case 4:
+			f = float64(math.Float32frombits(uint32(b.uint(p))))
+		case 8:
+			f = math.Float64frombits(b.uint(p))
+		default:
+			return nil, errBadPlist
+		}
+		if marker>>4 == 0x3 {
+			return plistEpoch.Add(time.Duration(f * float64(time.Second))), nil
+		}
+		return f, nil
+	}
+	n, start, err := b.length(o)
+	if err != nil {
+		return nil, err
+	}
+	switch marker >> 4 {
+	case 0x4:
+		p, err := b.slice(start, n)
+		return append([]byte{}, p...), err
+	case 0x5:
+		p, err := b.slice(start, n)
+		return string(p), err
+	case 0x6:
+		p, err := b.slice(start, n*2)
+		if err != nil {
+			return nil, err
+		}
+		u := make([]uint16, n)
+		for i := range u {
+			u[i] = binary.BigEndian.Uint16(p[i*2:])
+		}
+		return string(utf16.Decode(u)), nil
+	case 0xa:
+		refs, err := b.slice(start, n*uint64(b.refSize))
+		if err != nil {
+			return nil, err
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			if l[i], err = b.object(b.uint(refs[i*b.refSize : (i+1)*b.refSize])); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case 0xd:
+		refs, err := b.slice(start, 2*n*uint64(b.refSize))
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]interface{}{}
+		for i := uint64(0); i < n; i++ {
+			k, err := b.object(b.uint(refs[i*uint64(b.refSize) : (i+1)*uint64(b.refSize)]))
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errBadPlist
+			}
+			if m[key], err = b.object(b.uint(refs[(n+i)*uint64(b.refSize) : (n+i+1)*uint64(b.refSize)])); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return nil, errBadPlist
+}
+
+func (c *Config) marshalPlist(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var m interface{}
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+	b := &bytes.Buffer{}
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n")
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	c.writePlist(b, m, "")
+	b.WriteString("</plist>\n")
+	return b.Bytes(), nil
+}
+
+func (c *Config) writePlist(b *bytes.Buffer, v interface{}, indent string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k, e := range t {
+			if e != nil {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		b.WriteString(indent + "<dict>\n")
+		for _, k := range keys {
+			b.WriteString(indent + "\t<key>")
+			xml.EscapeText(b, []byte(k))
+			b.WriteString("</key>\n")
+			c.writePlist(b, t[k], indent+"\t")
+		}
+		b.WriteString(indent + "</dict>\n")
+	case []interface{}:
+		b.WriteString(indent + "<array>\n")
+		for _, e := range t {
+			if e != nil {
+				c.writePlist(b, e, indent+"\t")
+			}
+		}
+		b.WriteString(indent + "</array>\n")
+	case bool:
+		b.WriteString(indent + "<" + strconv.FormatBool(t) + "/>\n")
+	case json.Number:
+		if _, err := strconv.ParseInt(t.String(), 10, 64); err == nil {
+			b.WriteString(indent + "<integer>" + t.String() + "</integer>\n")
+		} else {
+			b.WriteString(indent + "<real>" + t.String() + "</real>\n")
+		}
+	case string:
+		b.WriteString(indent + "<string>")
+		xml.EscapeText(b, []byte(t))
+		b.WriteString("</string>\n")
+	}
+}
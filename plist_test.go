@@ -0,0 +1,192 @@
+package gonf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var xmlPlistData = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Blob</key>
+	<data>
+	aGk=
+	</data>
+	<key>Count</key>
+	<integer>42</integer>
+	<key>Enabled</key>
+	<true/>
+	<key>Name</key>
+	<string>gonf</string>
+	<key>Nested</key>
+	<dict>
+		<key>Key</key>
+		<string>välue</string>
+	</dict>
+	<key>Ratio</key>
+	<real>1.5</real>
+	<key>Tags</key>
+	<array>
+		<string>a</string>
+		<string>b</string>
+	</array>
+</dict>
+</plist>`
+
+var binaryPlistData = `YnBsaXN0MDDXAQIDBAUGBwgJCgsMDxBUQmxvYlVDb3VudFdFbmFibGVkVE5hbWVWTmVzdGVkVVJhdGlvVFRhZ3NCaGkQKglUZ29uZtENDlNLZXllAHYA5ABsAHUAZSM/+AAAAAAAAKIRElFhUWIIFxwiKi82PEFERkdMT1NeZ2psAAAAAAAAAQEAAAAAAAAAEwAAAAAAAAAAAAAAAAAAAG4=`
+
+func TestPlist(t *testing.T) {
+	expect := map[string]interface{}{
+		"Blob":    []byte("hi"),
+		"Count":   int64(42),
+		"Enabled": true,
+		"Name":    "gonf",
+		"Nested":  map[string]interface{}{"Key": "välue"},
+		"Ratio":   1.5,
+		"Tags":    []interface{}{"a", "b"},
+	}
+	c := &Config{}
+
+	if m, e := c.plist([]byte(xmlPlistData)); e != nil || !reflect.DeepEqual(m, expect) {
+		t.Errorf("failed to parse xml property list, %v %#v...", e, m)
+	}
+
+	b, _ := base64.StdEncoding.DecodeString(binaryPlistData)
+	if m, e := c.plist(b); e != nil || !reflect.DeepEqual(m, expect) {
+		t.Errorf("failed to parse binary property list, %v %#v...", e, m)
+	}
+
+	for _, bad := range []string{"", "<plist><array></array></plist>", "<plist><dict><string>x</string></dict></plist>", "bplist00", string(b[:len(b)-8])} {
+		if _, e := c.plist([]byte(bad)); e == nil {
+			t.Errorf("failed to reject invalid property list %q...", bad)
+		}
+	}
+}
+
+func TestPlistDefaults(t *testing.T) {
+	goos = "darwin"
+	defer func() { goos = runtime.GOOS }()
+	os.Clearenv()
+	os.Setenv("HOME", "/Users/test")
+	defer os.Clearenv()
+	os.Args = []string{}
+
+	user := filepath.Join("/Users/test", "Library", "Preferences", appName+".plist")
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.SetPaths("/nowhere")
+	if f := c.defaultFiles(); len(f) != 3 || f[1] != user || f[2] != filepath.Join("/Library", "Preferences", appName+".plist") {
+		t.Errorf("failed to include property list defaults, %v...", f)
+	}
+	readfile = func(n string) ([]byte, error) {
+		if n == user {
+			return []byte(`<plist><dict><key>OptionString</key><string>plist</string></dict></plist>`), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	if e := c.Load(); e != nil || c.ConfigFile() != user || mc.OptionString != "plist" {
+		t.Errorf("failed to load property list, %v...", e)
+	}
+}
+
+func TestSaveFormat(t *testing.T) {
+	c := &Config{}
+	c.Target(&struct {
+		Name    string
+		Count   int
+		Ratio   float64
+		Enabled bool
+		Tags    []string
+		Nested  struct{ Key string }
+		Empty   *string
+	}{Name: "a & b", Count: 42, Ratio: 1.5, Enabled: true, Tags: []string{"x"}, Nested: struct{ Key string }{"v"}})
+
+	if c.Format("yaml") == nil {
+		t.Error("failed to reject unsupported format...")
+	}
+
+	// test detection by extension
+	data, e := c.encode("/tmp/app.plist")
+	if e != nil {
+		t.Fatalf("failed to encode property list, %v...", e)
+	}
+	m, e := c.plist(data)
+	if e != nil || m["Name"] != "a & b" || m["Count"] != int64(42) || m["Ratio"] != 1.5 || m["Enabled"] != true || len(m["Tags"].([]interface{})) != 1 || m["Nested"].(map[string]interface{})["Key"] != "v" {
+		t.Errorf("failed to round-trip property list, %v %#v...", e, m)
+	}
+	if _, ok := m["Empty"]; ok {
+		t.Error("failed to omit null values from property list...")
+	}
+	if data, _ := c.encode("/tmp/app.json"); !strings.HasPrefix(string(data), "{") {
+		t.Error("failed to encode json by extension...")
+	}
+
+	// test explicit format
+	c.Format("json")
+	if data, _ := c.encode("/tmp/app.plist"); !strings.HasPrefix(string(data), "{") {
+		t.Error("failed to honor explicit format...")
+	}
+	c.Format("plist")
+	if data, _ := c.encode("/tmp/app.json"); !strings.HasPrefix(string(data), "<?xml") {
+		t.Error("failed to honor explicit format...")
+	}
+}
+
+// Build a binary property list from its objects, with a one byte offset table
+// and the trailer supplied.
+func mockBinaryPlist(refSize byte, count uint64, objects ...[]byte) []byte {
+	b := []byte("bplist00")
+	var offsets []byte
+	for _, o := range objects {
+		offsets = append(offsets, byte(len(b)))
+		b = append(b, o...)
+	}
+	table := uint64(len(b))
+	b = append(b, offsets...)
+	t := make([]byte, 32)
+	t[6], t[7] = 1, refSize
+	binary.BigEndian.PutUint64(t[8:], count)
+	binary.BigEndian.PutUint64(t[24:], table)
+	return append(b, t...)
+}
+
+func TestBinaryPlistMalformed(t *testing.T) {
+	huge := []byte{0x13, 0x40, 0, 0, 0, 0, 0, 0, 0}
+	malformed := map[string][]byte{
+		"object count":  mockBinaryPlist(1, 1<<62, []byte{0x08}),
+		"utf16 length":  mockBinaryPlist(1, 1, append([]byte{0x6f}, huge...)),
+		"array length":  mockBinaryPlist(4, 1, append([]byte{0xaf}, huge...)),
+		"dict length":   mockBinaryPlist(4, 1, append([]byte{0xdf}, huge...)),
+		"string length": mockBinaryPlist(1, 1, append([]byte{0x5f}, huge...)),
+		"cycle":         mockBinaryPlist(1, 1, []byte{0xa1, 0x00}),
+	}
+	c := &Config{}
+	for name, data := range malformed {
+		if _, e := c.plist(data); e == nil {
+			t.Errorf("failed to reject malformed %s...", name)
+		}
+	}
+	if m, e := c.plist(mockBinaryPlist(1, 3, []byte{0xd1, 0x01, 0x02}, []byte{0x51, 'k'}, []byte{0x09})); e != nil || m["k"] != true {
+		t.Errorf("failed to parse minimal binary property list, %v %v...", e, m)
+	}
+}
+
+func FuzzBinaryPlist(f *testing.F) {
+	b, _ := base64.StdEncoding.DecodeString(binaryPlistData)
+	f.Add(b)
+	f.Add(mockBinaryPlist(1, 3, []byte{0xd1, 0x01, 0x02}, []byte{0x51, 'k'}, []byte{0x09}))
+	c := &Config{}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c.plist(append([]byte("bplist00"), data...))
+	})
+}
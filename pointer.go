@@ -0,0 +1,23 @@
+package gonf
+
+import "strings"
+
+// Split a name into keys, either as an RFC 6901 JSON Pointer when it begins
+// with a slash (eg. `/hosts/example.com/port`), so that keys may contain dots,
+// or otherwise as dot-notation.
+func (c *Config) path(name string) ([]string, error) {
+	if !strings.HasPrefix(name, "/") {
+		if name == "" || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
+			return nil, errBadNameSyntax
+		}
+		return strings.Split(name, "."), nil
+	}
+	keys := strings.Split(name[1:], "/")
+	for i, k := range keys {
+		if k == "" || strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(k), "~") {
+			return nil, errBadNameSyntax
+		}
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(k)
+	}
+	return keys, nil
+}
@@ -0,0 +1,300 @@
+package gonf
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// A field of a struct as encoding/json sees it, with the index path through
+// any embedded structs that promote it.
+type jsonField struct {
+	name   string
+	tagged bool
+	index  []int
+}
+
+func (c *Config) jsonFields(t reflect.Type, index []int, depth int) []jsonField {
+	var list []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		idx := append(append([]int(nil), index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if tag == "-" {
+			continue
+		} else if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct && depth < maxDepth {
+			list = append(list, c.jsonFields(ft, idx, depth+1)...)
+			continue
+		} else if f.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			list = append(list, jsonField{f.Name, false, idx})
+		} else {
+			list = append(list, jsonField{tag, true, idx})
+		}
+	}
+	return list
+}
+
+// Find the field a key refers to the way encoding/json would, preferring the
+// shallowest exact match over a case-insensitive one, and allocating any nil
+// embedded pointers along the way.
+func (c *Config) jsonField(d reflect.Value, key string) (reflect.Value, bool) {
+	var best *jsonField
+	fields := c.jsonFields(d.Type(), nil, 0)
+	for _, exact := range []bool{true, false} {
+		for i, f := range fields {
+			if (exact && f.name == key) || (!exact && strings.EqualFold(f.name, key)) {
+				if best == nil || len(f.index) < len(best.index) || (len(f.index) == len(best.index) && f.tagged && !best.tagged) {
+					best = &fields[i]
+				}
+			}
+		}
+		if best != nil {
+			break
+		}
+	}
+	if best == nil {
+		return reflect.Value{}, false
+	}
+	for i, n := range best.index {
+		if i > 0 && d.Kind() == reflect.Ptr {
+			if d.IsNil() {
+				if !d.CanSet() {
+					return reflect.Value{}, false
+				}
+				d.Set(reflect.New(d.Type().Elem()))
+			}
+			d = d.Elem()
+		}
+		d = d.Field(n)
+	}
+	return d, d.CanSet()
+}
+
+// Decode a single value through json, for types with their own unmarshaling.
+func (c *Config) decodeValue(d reflect.Value, v interface{}, path string) []error {
+	data, _ := json.Marshal(v)
+	if err := json.Unmarshal(data, d.Addr().Interface()); err != nil {
+		return []error{fmt.Errorf("%w %v (%s)", ErrCast, err, path)}
+	}
+	return nil
+}
+
+func (c *Config) mismatch(d reflect.Value, v interface{}, path string) []error {
+	return []error{fmt.Errorf("%w %v into %s (%s)", ErrCast, v, d.Type(), path)}
+}
+
+// Return a number from a merged value, parsing strings from the command line
+// and environment.
+func (c *Config) numeric(d reflect.Value, v interface{}, path string) (float64, string, []error) {
+	switch t := v.(type) {
+	case string:
+		shown := t
+		if c.sensitiveKey(path) {
+			shown = redacted
+		}
+		if f, err := strconv.ParseFloat(t, 64); err == nil {
+			c.traced("converted %q to %s (%s)", shown, d.Kind(), path)
+			return f, t, nil
+		}
+		c.warning("unable to convert %q to %s", shown, d.Kind())
+		return 0, "", []error{fmt.Errorf("%w %q to %s", ErrCast, shown, d.Kind())}
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return 0, "", c.mismatch(d, v, path)
+		}
+		return f, t.String(), nil
+	}
+	r := reflect.ValueOf(v)
+	switch {
+	case r.CanInt():
+		return float64(r.Int()), strconv.FormatInt(r.Int(), 10), nil
+	case r.CanUint():
+		return float64(r.Uint()), strconv.FormatUint(r.Uint(), 10), nil
+	case r.CanFloat():
+		return r.Float(), strconv.FormatFloat(r.Float(), 'g', -1, 64), nil
+	}
+	return 0, "", c.mismatch(d, v, path)
+}
+
+// Populate a destination from a merged value while the lock is held, the way
+// json.Unmarshal would, converting strings from the command line and
+// environment and resetting it to its zero value for nil, without a json
+// round-trip through the target.  A panic from an unexpected kind or from an
+// unmarshaler is returned as an error for the field instead.
+func (c *Config) populate(d reflect.Value, v interface{}, path string) (errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errs = []error{fmt.Errorf("%w %v (%s)", ErrCast, r, path)}
+		}
+	}()
+	if path != "" && d.Kind() != reflect.Ptr && len(c.hooks) > 0 {
+		var assign bool
+		var err error
+		if v, assign, err = c.hooked(d, v, path); err != nil {
+			return []error{err}
+		} else if assign {
+			d.Set(reflect.ValueOf(v))
+			return nil
+		}
+	}
+	if v == nil {
+		d.Set(reflect.Zero(d.Type()))
+		return nil
+	} else if d.Kind() != reflect.Ptr && d.CanAddr() && (reflect.PtrTo(d.Type()).Implements(unmarshalerType) || reflect.PtrTo(d.Type()).Implements(textUnmarshalerType)) {
+		return c.decodeValue(d, v, path)
+	}
+	switch d.Kind() {
+	case reflect.Ptr:
+		if d.IsNil() {
+			d.Set(reflect.New(d.Type().Elem()))
+		}
+		return c.populate(d.Elem(), v, path)
+	case reflect.Interface:
+		if d.NumMethod() > 0 {
+			if errs, ok := c.variant(d, v, path); ok {
+				return errs
+			}
+			return c.decodeValue(d, v, path)
+		}
+		d.Set(reflect.ValueOf(v))
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return c.mismatch(d, v, path)
+		}
+		var errs []error
+		for k, e := range m {
+			if f, ok := c.jsonField(d, k); ok {
+				errs = append(errs, c.populate(f, e, c.join(path, k))...)
+			}
+		}
+		return errs
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return c.mismatch(d, v, path)
+		} else if d.Type().Key().Kind() != reflect.String || reflect.PtrTo(d.Type().Key()).Implements(textUnmarshalerType) {
+			return c.decodeValue(d, v, path)
+		}
+		if d.IsNil() {
+			d.Set(reflect.MakeMap(d.Type()))
+		}
+		var errs []error
+		for k, e := range m {
+			ev := reflect.New(d.Type().Elem()).Elem()
+			errs = append(errs, c.populate(ev, e, c.join(path, k))...)
+			d.SetMapIndex(reflect.ValueOf(k).Convert(d.Type().Key()), ev)
+		}
+		return errs
+	case reflect.Slice, reflect.Array:
+		if m, ok := v.(map[string]interface{}); ok {
+			if _, indexed := c.indexes(m); indexed {
+				return c.populateIndexes(d, m, path)
+			}
+		}
+		l, ok := v.([]interface{})
+		if !ok && d.Kind() == reflect.Slice && d.Type().Elem().Kind() == reflect.Uint8 {
+			return c.decodeValue(d, v, path)
+		} else if !ok {
+			return c.mismatch(d, v, path)
+		}
+		if d.Kind() == reflect.Slice {
+			d.Set(reflect.MakeSlice(d.Type(), len(l), len(l)))
+		}
+		var errs []error
+		for i := 0; i < d.Len(); i++ {
+			if i >= len(l) {
+				d.Index(i).Set(reflect.Zero(d.Type().Elem()))
+			} else if e := c.populate(d.Index(i), l[i], c.join(path, strconv.Itoa(i))); len(e) > 0 {
+				errs = append(errs, e...)
+			}
+		}
+		return errs
+	case reflect.Bool:
+		switch t := v.(type) {
+		case bool:
+			d.SetBool(t)
+		case string:
+			shown := t
+			if c.sensitiveKey(path) {
+				shown = redacted
+			}
+			r, err := strconv.ParseBool(t)
+			if err != nil {
+				c.warning("unable to convert %q to %s", shown, d.Kind())
+				return []error{fmt.Errorf("%w %q to %s", ErrCast, shown, d.Kind())}
+			}
+			c.traced("converted %q to %s (%s)", shown, d.Kind(), path)
+			d.SetBool(r)
+		default:
+			return c.mismatch(d, v, path)
+		}
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return c.mismatch(d, v, path)
+		}
+		d.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, s, errs := c.numeric(d, v, path)
+		if len(errs) > 0 {
+			return errs
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			n, err = int64(f), nil
+		}
+		if err != nil || d.OverflowInt(n) {
+			return c.mismatch(d, v, path)
+		}
+		d.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f, s, errs := c.numeric(d, v, path)
+		if len(errs) > 0 {
+			return errs
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil && f >= 0 && f == math.Trunc(f) && f < 1<<64 {
+			n, err = uint64(f), nil
+		}
+		if err != nil || d.OverflowUint(n) {
+			return c.mismatch(d, v, path)
+		}
+		d.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, _, errs := c.numeric(d, v, path)
+		if len(errs) > 0 {
+			return errs
+		} else if d.OverflowFloat(f) {
+			return c.mismatch(d, v, path)
+		}
+		d.SetFloat(f)
+	default:
+		return c.decodeValue(d, v, path)
+	}
+	return nil
+}
+
+func (c *Config) join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,109 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockPopulateEmbed struct {
+	Depth int `json:"depth"`
+}
+
+type mockPopulate struct {
+	*MockPopulateEmbed
+	Name      string            `json:"name"`
+	Small     int8              `json:"small"`
+	Count     uint              `json:"count"`
+	Ratio     float32           `json:"ratio"`
+	Enabled   *bool             `json:"enabled"`
+	When      time.Time         `json:"when"`
+	Address   net.IP            `json:"address"`
+	Labels    map[string]int    `json:"labels"`
+	Pair      [2]string         `json:"pair"`
+	Any       interface{}       `json:"any"`
+	Data      []byte            `json:"data"`
+	Nested    map[string]string `json:"-"`
+	marshaled bool
+}
+
+func (m *mockPopulate) MarshalJSON() ([]byte, error) {
+	m.marshaled = true
+	return []byte(`{}`), nil
+}
+
+func TestPopulate(t *testing.T) {
+	mp := &mockPopulate{Labels: map[string]int{"kept": 1}, Nested: map[string]string{"a": "b"}}
+	c := &Config{}
+	c.Target(mp)
+	e := c.to(map[string]interface{}{
+		"NAME":    "case",
+		"depth":   "3",
+		"small":   "12",
+		"count":   4.0,
+		"ratio":   "0.5",
+		"enabled": "true",
+		"when":    "2020-01-02T03:04:05Z",
+		"address": "10.0.0.1",
+		"labels":  map[string]interface{}{"added": "2"},
+		"pair":    []interface{}{"a"},
+		"any":     map[string]interface{}{"x": 1.0},
+		"data":    "aGk=",
+		"Nested":  map[string]interface{}{"c": "d"},
+	})
+	if e != nil || mp.Name != "case" || mp.MockPopulateEmbed == nil || mp.Depth != 3 || mp.Small != 12 || mp.Count != 4 || mp.Ratio != 0.5 || mp.Enabled == nil || !*mp.Enabled {
+		t.Errorf("failed to populate scalars, %v %+v...", e, mp)
+	}
+	if mp.When.Year() != 2020 || mp.Address.String() != "10.0.0.1" || mp.Labels["kept"] != 1 || mp.Labels["added"] != 2 || mp.Pair[0] != "a" || mp.Any == nil || string(mp.Data) != "hi" || len(mp.Nested) != 1 {
+		t.Errorf("failed to populate composites, %+v...", mp)
+	}
+	if mp.marshaled {
+		t.Error("failed to avoid marshaling the target...")
+	}
+
+	// test type errors leave fields untouched
+	e = c.to(map[string]interface{}{"small": 300.0, "count": -1.0, "name": 5.0, "pair": "x", "ratio": "abc", "depth": 1.5})
+	if !errors.Is(e, ErrCast) || len(e.(interface{ Unwrap() []error }).Unwrap()) != 6 || mp.Small != 12 || mp.Count != 4 || mp.Name != "case" || mp.Depth != 3 {
+		t.Errorf("failed to report type errors, %v %+v...", e, mp)
+	}
+
+	// test non-pointer targets
+	c.Target(mockPopulate{})
+	if e := c.to(map[string]interface{}{"name": "x"}); !errors.Is(e, ErrCast) {
+		t.Errorf("failed to reject non-pointer target, %v...", e)
+	}
+}
+
+type mockPanics struct{}
+
+func (m *mockPanics) UnmarshalJSON([]byte) error {
+	panic("unmarshaler panicked")
+}
+
+type mockOddKinds struct {
+	Channel  chan int     `json:"channel"`
+	Func     func()       `json:"func"`
+	Complex  complex64    `json:"complex"`
+	Panics   mockPanics   `json:"panics"`
+	Stringer fmt.Stringer `json:"stringer"`
+	Name     string       `json:"name"`
+	hidden   string
+}
+
+func TestPopulatePanics(t *testing.T) {
+	mo := &mockOddKinds{}
+	c := &Config{}
+	c.Target(mo)
+	e := c.to(map[string]interface{}{"channel": 1, "func": "f", "complex": 2, "panics": "p", "stringer": "s", "name": "kept", "hidden": "h"})
+	if !errors.Is(e, ErrCast) || mo.Name != "kept" || mo.hidden != "" {
+		t.Errorf("failed to report odd kinds as errors, %v %+v...", e, mo)
+	}
+	for _, f := range []string{"(channel)", "(func)", "(complex)", "unmarshaler panicked (panics)", "(stringer)"} {
+		if !strings.Contains(e.Error(), f) {
+			t.Errorf("failed to report %s as an error for the field, %v...", f, e)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gonf
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Identical to Load, except that the file data is read from the supplied
+// reader in the format, either "json" or "plist" (json when empty), instead of
+// searching the file system, so tests, embedded defaults, and network
+// handlers can supply configuration directly.  The `--config` option is
+// ignored and no defaults are saved.
+func (c *Config) LoadReader(r io.Reader, format string) error {
+	if format == "" {
+		format = "json"
+	} else if format != "json" && format != "plist" {
+		return errUnknownFormat
+	}
+	return c.load(context.Background(), func() (map[string]interface{}, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return map[string]interface{}{}, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if data, _, err = c.decrypt(data); err != nil {
+			return map[string]interface{}{}, err
+		}
+		c.lines = nil
+		if c.schema != nil && format == "json" {
+			c.lines = c.locate(data)
+		}
+		vars, err := c.decode("reader."+format, data)
+		if err != nil {
+			return vars, fmt.Errorf("%w reader: %v", ErrParse, err)
+		}
+		if c.sources == nil {
+			c.sources = map[string]map[string]string{}
+		}
+		c.sources["file"] = c.renameOrigins(c.keyMapper, c.origins("reader", vars, map[string]string{}))
+		return vars, nil
+	})
+}
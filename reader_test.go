@@ -0,0 +1,47 @@
+package gonf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockReader struct{}
+
+func (mockReader) Read([]byte) (int, error) { return 0, mockError }
+
+func TestLoadReader(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Args = []string{"app", "--number", "3"}
+	readfile = nil
+	stat = nil
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Add("OptionNumber", "", "", "--number")
+	if c.LoadReader(strings.NewReader(`{}`), "yaml") == nil {
+		t.Error("failed to reject unknown format...")
+	}
+
+	// test json with options taking precedence
+	if e := c.LoadReader(strings.NewReader(`{"OptionNumber": 1, "EnvString": "reader"}`), ""); e != nil || mc.OptionNumber != 3 || mc.EnvString != "reader" || c.source("EnvString") != "reader" {
+		t.Errorf("failed to load from reader, %v %+v...", e, mc)
+	}
+
+	// test plist
+	plist := `<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict><key>EnvString</key><string>plist</string></dict></plist>`
+	if e := c.LoadReader(strings.NewReader(plist), "plist"); e != nil || mc.EnvString != "plist" {
+		t.Errorf("failed to load plist from reader, %v...", e)
+	}
+
+	// test read and parse errors
+	if e := c.LoadReader(mockReader{}, "json"); !errors.Is(e, mockError) {
+		t.Errorf("failed to report read error, %v...", e)
+	}
+	if e := c.LoadReader(strings.NewReader(`not json`), "json"); !errors.Is(e, ErrParse) {
+		t.Errorf("failed to report parse error, %v...", e)
+	}
+}
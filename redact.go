@@ -0,0 +1,113 @@
+package gonf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+func (c *Config) tagged(t reflect.Type, prefix, flag string) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || strings.Count(prefix, ".") > maxDepth {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := strings.Split(f.Tag.Get("json"), ",")[0]
+		if n == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		} else if n == "" && f.Anonymous {
+			names = append(names, c.tagged(f.Type, prefix, flag)...)
+			continue
+		} else if n == "" {
+			n = f.Name
+		}
+		if prefix != "" {
+			n = prefix + "." + n
+		}
+		for _, o := range strings.Split(f.Tag.Get("gonf"), ",") {
+			if strings.TrimSpace(o) == flag {
+				names = append(names, n)
+			}
+		}
+		names = append(names, c.tagged(f.Type, n, flag)...)
+	}
+	return names
+}
+
+func (c *Config) sensitiveKey(key string) bool {
+	names := c.tagged(reflect.TypeOf(c.target), "", "sensitive")
+	for n := range c.sensitive {
+		names = append(names, n)
+	}
+	for _, n := range names {
+		if key == n || strings.HasPrefix(key, n+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) isSensitive(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sensitiveKey(key)
+}
+
+func (c *Config) redacted(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if o, ok := m.(map[string]interface{}); ok && c.redact {
+		c.omit("", o)
+	}
+	if o, ok := m.(map[string]interface{}); ok {
+		c.restore(o)
+	}
+	return m, nil
+}
+
+func (c *Config) omit(prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if c.sensitiveKey(path) {
+			delete(m, k)
+		} else if o, ok := v.(map[string]interface{}); ok {
+			c.omit(path, o)
+		}
+	}
+}
+
+// Mark registered names as sensitive so their values are redacted from any
+// reported changes, help output, and generated documentation.  Properties
+// may also be marked with a `gonf:"sensitive"` struct tag.
+func (c *Config) Sensitive(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sensitive == nil {
+		c.sensitive = map[string]struct{}{}
+	}
+	for _, n := range names {
+		c.sensitive[n] = struct{}{}
+	}
+}
+
+// When enabled, Save omits sensitive values from the file, which is useful
+// when the file is stored in a shared location and secrets are supplied by
+// environment variables.
+func (c *Config) Redact(enabled bool) {
+	c.mu.Lock()
+	c.redact = enabled
+	c.mu.Unlock()
+}
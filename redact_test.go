@@ -0,0 +1,64 @@
+package gonf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockSensitive struct {
+	Public   string
+	Password string `gonf:"sensitive"`
+	Database struct {
+		Host string
+		Key  string `json:"key" gonf:"sensitive"`
+	}
+	Credentials struct {
+		Token string
+	} `gonf:"sensitive"`
+}
+
+func TestSensitive(t *testing.T) {
+	ms := &mockSensitive{Public: "public", Password: "secret"}
+	ms.Database.Host, ms.Database.Key, ms.Credentials.Token = "host", "key", "token"
+	c := &Config{}
+	c.Target(ms)
+	c.Sensitive("Public.Nothing")
+	for k, v := range map[string]bool{"Public": false, "Password": true, "Database.Host": false, "Database.key": true, "Credentials": true, "Credentials.Token": true, "Public.Nothing": true} {
+		if c.isSensitive(k) != v {
+			t.Errorf("failed to identify sensitivity of %s...", k)
+		}
+	}
+
+	// test redacted save
+	if data, _ := c.encode("test.json"); !strings.Contains(string(data), "secret") {
+		t.Error("failed to save sensitive values by default...")
+	}
+	c.Redact(true)
+	data, e := c.encode("test.json")
+	var m map[string]interface{}
+	if e != nil || json.Unmarshal(data, &m) != nil || m["Public"] != "public" || m["Password"] != nil || m["Credentials"] != nil ||
+		m["Database"].(map[string]interface{})["Host"] != "host" || m["Database"].(map[string]interface{})["key"] != nil {
+		t.Errorf("failed to omit sensitive values, %v %s...", e, data)
+	}
+
+	// test redacted documentation and help
+	c.Add("Password", "the password", "APP_PASSWORD")
+	if md := c.Markdown(); strings.Contains(md, "secret") || !strings.Contains(md, redacted) {
+		t.Errorf("failed to redact markdown defaults, %s...", md)
+	}
+	var fmtPrintfData string
+	fmtPrintf = func(f string, a ...interface{}) (int, error) {
+		fmtPrintfData += fmt.Sprintf(f, a...)
+		return 0, nil
+	}
+	os.Setenv("APP_PASSWORD", "environment")
+	defer os.Clearenv()
+	c.Description("testing sensitive")
+	c.Help()
+	if strings.Contains(fmtPrintfData, "environment") {
+		t.Error("failed to redact sensitive environment values from help...")
+	}
+}
@@ -0,0 +1,60 @@
+package gonf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+var errNotObject = errors.New("document is not an object, name a key for it with Root...")
+
+// Place a decoded document that is not an object beneath the Root key.
+func (c *Config) rooted(v interface{}) (map[string]interface{}, error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, nil
+	} else if v == nil {
+		return map[string]interface{}{}, nil
+	}
+	root, _ := c.root.Load().(string)
+	if root == "" {
+		return nil, errNotObject
+	}
+	m := map[string]interface{}{}
+	c.set(m, root, v)
+	return m, nil
+}
+
+// Return the current configuration as a map while the lock is held, with a
+// target that is not a struct beneath the Root key.
+func (c *Config) document() map[string]interface{} {
+	var v interface{}
+	json.Unmarshal(c.marshal(), &v)
+	if m, err := c.rooted(v); err == nil && m != nil {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+// Populate a target that is not a struct or map, such as a slice, from the
+// value of the Root key while the lock is held, reporting whether it was one.
+func (c *Config) populateRoot(dst interface{}, combo map[string]interface{}) ([]error, bool) {
+	root, _ := c.root.Load().(string)
+	t := reflect.TypeOf(dst)
+	if root == "" || t.Kind() != reflect.Ptr || t.Elem().Kind() == reflect.Struct || t.Elem().Kind() == reflect.Map || t.Elem().Kind() == reflect.Interface {
+		return nil, false
+	}
+	v, ok := c.lookup(combo, root)
+	if !ok {
+		return nil, true
+	}
+	return c.populate(reflect.ValueOf(dst).Elem(), v, root), true
+}
+
+// Name the dot-notation key that holds configuration files, defaults, and
+// readers whose document is an array or scalar instead of an object, so that
+// tools consuming lists, such as job definitions, can be configured.  A target
+// that is a pointer to a slice, array, or scalar is populated from that key,
+// and is saved as the document itself.
+func (c *Config) Root(name string) {
+	c.root.Store(name)
+}
@@ -0,0 +1,57 @@
+package gonf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockJob struct {
+	Name     string
+	Schedule string
+}
+
+func TestRoot(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	data, modTime := `[{"Name": "backup", "Schedule": "daily"}]`, time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	// test documents that are not objects are rejected without a root
+	if e := (&Config{}).Load("/tmp/jobs.json"); !errors.Is(e, ErrParse) {
+		t.Errorf("failed to reject array document, %v...", e)
+	}
+
+	var jobs []mockJob
+	c := &Config{}
+	c.Target(&jobs)
+	c.Root("jobs")
+	if e := c.Load("/tmp/jobs.json"); e != nil || len(jobs) != 1 || jobs[0].Name != "backup" {
+		t.Errorf("failed to load array document into slice target, %v %+v...", e, jobs)
+	}
+	if v, ok := c.Get("jobs.0.Schedule"); !ok || v != "daily" {
+		t.Errorf("failed to get value beneath root, %v...", v)
+	}
+
+	// test reloads report changes beneath the root
+	var changes ChangeSet
+	c.OnReload(func(cs ChangeSet) { changes = cs })
+	data, modTime = `[{"Name": "backup", "Schedule": "hourly"}, {"Name": "vacuum"}]`, modTime.Add(time.Second)
+	if e := c.Reload(); e != nil || len(jobs) != 2 || jobs[0].Schedule != "hourly" || len(changes) != 1 || changes[0].Key != "jobs" {
+		t.Errorf("failed to reload array document, %v %+v %v...", e, jobs, changes)
+	}
+
+	// test scalar documents populate the root key of a struct target
+	data = `"scalar"`
+	mc := &mockConfig{}
+	c = &Config{}
+	c.Target(mc)
+	c.Root("ExplicitComposite.TripleDepth")
+	if e := c.Load("/tmp/value.json"); e != nil || mc.ExplicitComposite.TripleDepth != "scalar" {
+		t.Errorf("failed to load scalar document beneath root, %v %+v...", e, mc)
+	}
+}
@@ -0,0 +1,324 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The subset of JSON Schema used to check configuration files.
+type schema struct {
+	Type                 interface{}        `json:"type,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	pattern              *regexp.Regexp
+}
+
+// Generate a schema describing the json representation of a type.
+func (c *Config) generate(t reflect.Type, depth int) *schema {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := &schema{}
+	if t == nil || depth > maxDepth || reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) || reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		return s
+	}
+	switch {
+	case t.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case t.Kind() == reflect.String:
+		s.Type = "string"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s.Type = "number"
+	case c.isNumeric(t.Kind()):
+		s.Type = "integer"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		s.Type = "string"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s.Type, s.Items = "array", c.generate(t.Elem(), depth+1)
+	case t.Kind() == reflect.Map:
+		s.Type = "object"
+	case t.Kind() == reflect.Struct:
+		s.Type, s.Properties = "object", map[string]*schema{}
+		c.properties(t, s.Properties, depth)
+	}
+	return s
+}
+
+func (c *Config) properties(t reflect.Type, out map[string]*schema, depth int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := strings.Split(f.Tag.Get("json"), ",")[0]
+		if n == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		} else if ft := f.Type; n == "" && f.Anonymous {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				c.properties(ft, out, depth)
+			}
+			continue
+		} else if n == "" {
+			n = f.Name
+		}
+		if _, ok := out[n]; !ok {
+			p := c.generate(f.Type, depth+1)
+			if p.Type != nil {
+				p.Type = []interface{}{p.Type, "null"}
+			}
+			out[n] = p
+		}
+	}
+}
+
+func (c *Config) compile(s *schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		r, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = r
+	}
+	for _, p := range s.Properties {
+		if err := c.compile(p); err != nil {
+			return err
+		}
+	}
+	return c.compile(s.Items)
+}
+
+func (c *Config) typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := t.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return ""
+}
+
+// Describe the types a schema allows, such as "integer or null".
+func (c *Config) types(s *schema) string {
+	if l, ok := s.Type.([]interface{}); ok {
+		names := make([]string, len(l))
+		for i, e := range l {
+			names[i] = fmt.Sprint(e)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(s.Type)
+}
+
+func (c *Config) typed(s *schema, v interface{}) bool {
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, e := range t {
+			if n, ok := e.(string); ok {
+				types = append(types, n)
+			}
+		}
+	default:
+		return true
+	}
+	actual := c.typeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) number(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Check a value against a schema, returning a message for each violation
+// keyed by the dot-notation path it was found at.
+func (c *Config) conform(s *schema, path string, v interface{}, out map[string]string) {
+	if s == nil {
+		return
+	} else if !c.typed(s, v) {
+		out[path] = fmt.Sprintf("expected %s but found %s", c.types(s), c.typeOf(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			a, _ := json.Marshal(e)
+			b, _ := json.Marshal(v)
+			found = found || bytes.Equal(a, b)
+		}
+		if !found {
+			out[path] = fmt.Sprintf("expected one of %v", s.Enum)
+			return
+		}
+	}
+	if n, ok := c.number(v); ok && s.Minimum != nil && n < *s.Minimum {
+		out[path] = fmt.Sprintf("expected at least %v", *s.Minimum)
+	} else if ok && s.Maximum != nil && n > *s.Maximum {
+		out[path] = fmt.Sprintf("expected at most %v", *s.Maximum)
+	}
+	if str, ok := v.(string); ok {
+		if l := len([]rune(str)); s.MinLength != nil && l < *s.MinLength {
+			out[path] = fmt.Sprintf("expected at least %d characters", *s.MinLength)
+		} else if s.MaxLength != nil && l > *s.MaxLength {
+			out[path] = fmt.Sprintf("expected at most %d characters", *s.MaxLength)
+		} else if s.pattern != nil && !s.pattern.MatchString(str) {
+			out[path] = fmt.Sprintf("expected to match %s", s.Pattern)
+		}
+	}
+	if l, ok := v.([]interface{}); ok {
+		if s.MinItems != nil && len(l) < *s.MinItems {
+			out[path] = fmt.Sprintf("expected at least %d items", *s.MinItems)
+		} else if s.MaxItems != nil && len(l) > *s.MaxItems {
+			out[path] = fmt.Sprintf("expected at most %d items", *s.MaxItems)
+		}
+		for i, e := range l {
+			c.conform(s.Items, fmt.Sprintf("%s.%d", path, i), e, out)
+		}
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		prefix := path
+		if prefix != "" {
+			prefix += "."
+		}
+		for _, r := range s.Required {
+			if _, ok := m[r]; !ok {
+				out[prefix+r] = "required property is missing"
+			}
+		}
+		for k, e := range m {
+			if p, ok := s.Properties[k]; ok {
+				c.conform(p, prefix+k, e, out)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				out[prefix+k] = "property is not allowed"
+			}
+		}
+	}
+}
+
+// Check the configuration read from files against the schema, removing any
+// value that does not conform so it cannot be applied.
+func (c *Config) checkSchema(vars map[string]interface{}) error {
+	c.mu.RLock()
+	s, lines, name := c.schema, c.lines, c.configFile
+	c.mu.RUnlock()
+	if s == nil || vars == nil {
+		return nil
+	}
+	out := map[string]string{}
+	c.conform(s, "", vars, out)
+	paths := make([]string, 0, len(out))
+	for p := range out {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var errs []error
+	for _, p := range paths {
+		target, segments := p, strings.Split(p, ".")
+		for i := range segments {
+			if v, _ := c.lookup(vars, strings.Join(segments[:i+1], ".")); v != nil {
+				if _, ok := v.([]interface{}); ok {
+					target = strings.Join(segments[:i+1], ".")
+					break
+				}
+			}
+		}
+		c.unset(vars, target)
+		l, ok := lines[p]
+		for k := p; !ok && strings.Contains(k, "."); {
+			k = k[:strings.LastIndex(k, ".")]
+			l, ok = lines[k]
+		}
+		if ok {
+			errs = append(errs, fmt.Errorf("%w %s:%d: %s %s", ErrSchema, name, l, p, out[p]))
+		} else {
+			errs = append(errs, fmt.Errorf("%w %s: %s %s", ErrSchema, name, p, out[p]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Return the line number of each key in a raw configuration file.
+func (c *Config) locate(data []byte) map[string]int {
+	lines := map[string]int{}
+	c.scanJSON(data, func(path string, offset int) {
+		if _, ok := lines[path]; !ok {
+			lines[path] = bytes.Count(data[:offset], []byte("\n")) + 1
+		}
+	}, func(string) {})
+	return lines
+}
+
+// Check configuration files against a JSON Schema before they are merged, so
+// structural mistakes are reported as ErrSchema with the path and line of
+// each problem, instead of becoming silent zero values.  Values that do not
+// conform are discarded.  Without a schema, one is generated from the target,
+// which checks the type of every property while allowing null to reset it.  The supported keywords are type,
+// properties, required, additionalProperties, items, enum, minimum, maximum,
+// minLength, maxLength, minItems, maxItems, and pattern.
+func (c *Config) Schema(data []byte) error {
+	s := &schema{}
+	if len(data) == 0 {
+		c.mu.RLock()
+		s = c.generate(reflect.TypeOf(c.target), 0)
+		c.mu.RUnlock()
+	} else if err := json.Unmarshal(data, s); err != nil {
+		return err
+	}
+	if err := c.compile(s); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.schema = s
+	c.mu.Unlock()
+	return nil
+}
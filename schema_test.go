@@ -0,0 +1,70 @@
+package gonf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockSchema struct {
+	Port    int      `json:"port"`
+	Name    string   `json:"name"`
+	Ratio   float64  `json:"ratio"`
+	Enabled bool     `json:"enabled"`
+	Hosts   []string `json:"hosts"`
+	Nested  struct {
+		Level int `json:"level"`
+	} `json:"nested"`
+}
+
+func TestSchema(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	data := "{\n\t\"port\": \"80\",\n\t\"name\": \"app\",\n\t\"ratio\": 1.5,\n\t\"hosts\": [\"a\", 2],\n\t\"nested\": {\n\t\t\"level\": 1.5\n\t}\n}"
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	ms := &mockSchema{Port: 8080, Hosts: []string{"default"}}
+	c := &Config{}
+	c.Target(ms)
+	if c.Schema([]byte(`{"pattern": "["}`)) == nil || c.Schema([]byte(`not json`)) == nil {
+		t.Error("failed to reject bad schema...")
+	}
+
+	// test generated schema reports lines and discards values
+	c.Schema(nil)
+	e := c.Load("/tmp/app.json")
+	for _, m := range []string{"app.json:2: port expected integer or null but found string", "app.json:5: hosts.1 expected string", "app.json:7: nested.level expected integer or null but found number"} {
+		if !errors.Is(e, ErrSchema) || !strings.Contains(e.Error(), m) {
+			t.Errorf("failed to report %s, %v...", m, e)
+		}
+	}
+	if ms.Port != 8080 || ms.Name != "app" || ms.Ratio != 1.5 || len(ms.Hosts) != 1 || ms.Hosts[0] != "default" {
+		t.Errorf("failed to discard nonconforming values, %+v...", ms)
+	}
+
+	// test null resets a property under the generated schema
+	data = `{"name": null}`
+	ms.Name = "app"
+	if e := c.Load("/tmp/app.json"); e != nil || ms.Name != "" {
+		t.Errorf("failed to accept null under the generated schema, %v %+v...", e, ms)
+	}
+
+	// test supplied schema
+	data = `{"port": 99999, "name": "x", "extra": true}`
+	c.Schema([]byte(`{"type": "object", "required": ["enabled"], "additionalProperties": false, "properties": {"port": {"type": "integer", "maximum": 65535}, "name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"}, "enabled": {"type": "boolean"}}}`))
+	e = c.Load("/tmp/app.json")
+	for _, m := range []string{"port expected at most 65535", "name expected at least 2 characters", "extra property is not allowed", "enabled required property is missing"} {
+		if !errors.Is(e, ErrSchema) || !strings.Contains(e.Error(), m) {
+			t.Errorf("failed to report %s, %v...", m, e)
+		}
+	}
+
+	// test conforming files
+	data = `{"port": 80, "name": "app", "enabled": true}`
+	if e := c.Load("/tmp/app.json"); e != nil || ms.Port != 80 {
+		t.Errorf("failed to accept conforming file, %v...", e)
+	}
+}
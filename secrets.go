@@ -0,0 +1,138 @@
+package gonf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errBadReference = errors.New("unable to resolve secret reference...")
+	errNoCommands   = errors.New("command references have not been enabled...")
+
+	command = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, name, args...).Output()
+	}
+)
+
+// A value from a configuration file that was resolved from a reference, so
+// that Save can restore the reference instead of writing the secret.
+type reference struct {
+	ref   string
+	value string
+}
+
+func (c *Config) dereference(ctx context.Context, ref string) (string, bool, error) {
+	var out []byte
+	var err error
+	c.mu.RLock()
+	resolving, enabled := c.resolving, c.commands
+	c.mu.RUnlock()
+	if !resolving && !enabled {
+		return ref, false, nil
+	}
+	switch {
+	case !resolving && !strings.HasPrefix(ref, "cmd://"):
+		return ref, false, nil
+	case strings.HasPrefix(ref, "file://"):
+		name := strings.TrimPrefix(ref, "file://")
+		err = c.withContext(ctx, func() (e error) { out, e = readfile(name); return })
+	case strings.HasPrefix(ref, "credential://"):
+		dir, name := os.Getenv("CREDENTIALS_DIRECTORY"), strings.TrimPrefix(ref, "credential://")
+		if dir == "" || name == "" || strings.ContainsAny(name, `/\`) {
+			return "", true, errBadReference
+		}
+		err = c.withContext(ctx, func() (e error) { out, e = readfile(filepath.Join(dir, name)); return })
+	case strings.HasPrefix(ref, "env://"):
+		v, ok := os.LookupEnv(strings.TrimPrefix(ref, "env://"))
+		if !ok {
+			return "", true, errBadReference
+		}
+		return v, true, nil
+	case strings.HasPrefix(ref, "cmd://"):
+		args := strings.Fields(strings.TrimPrefix(ref, "cmd://"))
+		if !enabled {
+			return "", true, errNoCommands
+		} else if len(args) == 0 {
+			return "", true, errBadReference
+		}
+		out, err = command(ctx, args[0], args[1:]...)
+	default:
+		return ref, false, nil
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("%w %v", errBadReference, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), true, nil
+}
+
+func (c *Config) resolve(ctx context.Context, prefix string, in map[string]interface{}, refs map[string]reference) (map[string]interface{}, error) {
+	var errs []error
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			m, err := c.resolve(ctx, path, t, refs)
+			out[k], errs = m, append(errs, err)
+		case string:
+			r, ok, err := c.dereference(ctx, t)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			} else if ok {
+				out[k], refs[path] = r, reference{ref: t, value: r}
+			} else {
+				out[k] = v
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func (c *Config) resolveReferences(ctx context.Context, vars map[string]interface{}) (map[string]interface{}, error) {
+	refs := map[string]reference{}
+	out, err := c.resolve(ctx, "", vars, refs)
+	c.mu.Lock()
+	c.references = refs
+	c.mu.Unlock()
+	return out, err
+}
+
+func (c *Config) restore(m map[string]interface{}) {
+	for k, r := range c.references {
+		if v, ok := c.lookup(m, k); (ok && fmt.Sprint(v) == r.value) || (!ok && c.redact && c.sensitiveKey(k)) {
+			c.set(m, k, r.ref)
+		}
+	}
+}
+
+// Enable resolution of `file://`, `env://`, and `credential://` references in
+// configuration files, which read the named file, environment variable, or
+// systemd credential and use it as the value.  Since any string could read
+// from the system, it is disabled by default and such values are kept as
+// written.
+func (c *Config) References(enabled bool) {
+	c.mu.Lock()
+	c.resolving = enabled
+	c.mu.Unlock()
+}
+
+// Enable resolution of `cmd://` references in configuration files, which run
+// the command that follows without a shell and use its output as the value.
+// Because this executes whatever is written in the file, it is disabled by
+// default, and a `cmd://` reference is reported as an error while only the
+// other references are enabled.
+func (c *Config) Commands(enabled bool) {
+	c.mu.Lock()
+	c.commands = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,66 @@
+package gonf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReferences(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("DB_PASS", "environment")
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(n string) ([]byte, error) {
+		switch n {
+		case "/tmp/app.json":
+			return []byte(`{"OptionString": "file:///run/secrets/token", "EnvString": "env://DB_PASS", "ExplicitComposite": {"TripleDepth": "cmd://pass show app"}, "OptionNumber": 1}`), nil
+		case "/run/secrets/token":
+			return []byte("secret\n"), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	var ran []string
+	command = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		ran = append([]string{name}, args...)
+		return []byte("output\n"), nil
+	}
+
+	// test references are kept as written by default
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "file:///run/secrets/token" || mc.EnvString != "env://DB_PASS" {
+		t.Errorf("failed to keep references as written by default, %v %+v...", e, mc)
+	}
+
+	// test enabled references
+	c.References(true)
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, errNoCommands) || mc.OptionString != "secret" || mc.EnvString != "environment" || mc.OptionNumber != 1 {
+		t.Errorf("failed to resolve references, %v %+v...", e, mc)
+	}
+	if ran != nil {
+		t.Error("failed to disable command references by default...")
+	}
+
+	// test that saving restores unchanged references
+	mc.EnvString = "changed"
+	data, _ := c.encode("/tmp/app.json")
+	if !strings.Contains(string(data), "file:///run/secrets/token") || strings.Contains(string(data), `"secret"`) || !strings.Contains(string(data), "changed") {
+		t.Errorf("failed to restore references on save, %s...", data)
+	}
+
+	// test missing references and enabled commands
+	os.Unsetenv("DB_PASS")
+	c.Commands(true)
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	if e := c.Reload(); !errors.Is(e, errBadReference) || strings.Join(ran, " ") != "pass show app" {
+		t.Errorf("failed to resolve command or report missing reference, %v %v...", e, ran)
+	}
+}
@@ -10,27 +10,90 @@ type setting struct {
 	Description string
 	Env         string
 	Options     []string
+	Choices     []string
+	Group       string
+	Hidden      bool
+	OldEnv      []string
+	OldOptions  []string
+	Prefixed    bool
+}
+
+// A command line use case, and what it demonstrates.
+type example struct {
+	Usage       string
+	Description string
 }
 
 // Check for a matching option, and whether that option is greedy.
 func (s *setting) Match(exists string) (bool, bool) {
-	for _, o := range s.Options {
-		if o == exists {
-			return true, false
-		} else if o == exists+":" {
-			return true, true
+	for _, l := range [][]string{s.Options, s.OldOptions} {
+		for _, o := range l {
+			if o == exists {
+				return true, false
+			} else if o == exists+":" {
+				return true, true
+			}
 		}
 	}
 	return false, false
 }
 
-// Format the combined environment and command line options for a setting.
-func (s setting) String() string {
+// Check whether an environment variable or command line option is one of the
+// deprecated aliases of the setting.
+func (s *setting) Obsolete(used string) bool {
+	for _, o := range append(append([]string{}, s.OldEnv...), s.OldOptions...) {
+		if o == used || o == used+":" {
+			return true
+		}
+	}
+	return false
+}
+
+// Combine the deprecated command line options and environment variables.
+func (s *setting) Aliases() string {
+	a := setting{Env: strings.Join(s.OldEnv, ", "), Options: s.OldOptions}
+	return a.Flags()
+}
+
+// Check whether a value, or every element of a list, is an allowed choice.
+func (s *setting) Allows(v interface{}) bool {
+	if l, ok := v.([]interface{}); ok {
+		for _, e := range l {
+			if !s.Allows(e) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, c := range s.Choices {
+		if c == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Combine the command line options and environment variable for a setting.
+func (s *setting) Flags() string {
 	o := strings.Replace(strings.Join(s.Options, ", "), ":", "", -1)
 	if o == "" {
 		o = s.Env
 	} else if s.Env != "" {
 		o += " (" + s.Env + ")"
 	}
-	return fmt.Sprintf("\t%-30s\n\t\t%s", o, s.Description)
+	return o
+}
+
+// Format the combined environment and command line options for a setting.
+func (s setting) String() string {
+	return s.format(plain)
+}
+
+func (s setting) format(paint painter) string {
+	o := paint(green, fmt.Sprintf("%-30s", s.Flags()))
+	d := s.Description
+	if len(s.Choices) > 0 {
+		d += " (" + paint(yellow, strings.Join(s.Choices, ", ")) + ")"
+	}
+	return fmt.Sprintf("\t%s\n\t\t%s", o, d)
 }
@@ -0,0 +1,53 @@
+package gonf
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Log the effective configuration with sensitive values masked, as a single
+// event when slog is configured, or as text through the target logger.
+func (c *Config) logDump() {
+	var attrs []slog.Attr
+	for _, e := range c.entries() {
+		attrs = append(attrs, slog.Any(e.Key, e.Value))
+	}
+	if c.event(slog.LevelInfo, "configuration dump", attrs...) {
+		return
+	}
+	b := &bytes.Buffer{}
+	c.Dump(b, "text")
+	c.notify(slog.LevelInfo, "configuration dump:\n%s", b)
+}
+
+// Log the effective configuration, with sensitive values masked, each time
+// the process receives SIGUSR1, mirroring the reload on SIGHUP pattern for
+// live debugging of long-running daemons.  The returned function stops the
+// handler.  Platforms without SIGUSR1, such as windows, are ignored.
+func (c *Config) DumpOnSignal() (stop func()) {
+	if dumpSignal == nil {
+		return func() {}
+	}
+	ch, done := make(chan os.Signal, 1), make(chan struct{})
+	signal.Notify(ch, dumpSignal)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				c.logDump()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package gonf
+
+import "os"
+
+var dumpSignal os.Signal
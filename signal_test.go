@@ -0,0 +1,42 @@
+//go:build unix
+
+package gonf
+
+import (
+	"log/slog"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type mockWriter chan string
+
+func (w mockWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestDumpOnSignal(t *testing.T) {
+	w := make(mockWriter, 1)
+	c := &Config{}
+	c.Target(&mockConfig{EnvString: "hidden", OptionString: "visible"})
+	c.Sensitive("EnvString")
+	c.Logger(slog.New(slog.NewTextHandler(w, nil)))
+	stop := c.DumpOnSignal()
+
+	// test the dump is logged on SIGUSR1
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case out := <-w:
+		if !strings.Contains(out, "configuration dump") || !strings.Contains(out, "OptionString=visible") || strings.Contains(out, "hidden") {
+			t.Errorf("failed to mask the configuration, %s...", out)
+		}
+	case <-time.After(time.Second):
+		t.Error("failed to log the configuration on signal...")
+	}
+
+	// test stop is safe to repeat
+	stop()
+	stop()
+}
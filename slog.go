@@ -0,0 +1,41 @@
+package gonf
+
+import (
+	"context"
+	"log/slog"
+)
+
+func (c *Config) event(level slog.Level, msg string, attrs ...slog.Attr) bool {
+	c.mu.RLock()
+	l := c.slog
+	c.mu.RUnlock()
+	if l == nil {
+		return false
+	}
+	l.LogAttrs(context.Background(), level, msg, attrs...)
+	return true
+}
+
+func (c *Config) level(err error) slog.Level {
+	if err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+func (c *Config) errorAttr(attrs []slog.Attr, err error) []slog.Attr {
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	return attrs
+}
+
+// Supply a structured logger, which takes the place of any Info and Debug
+// functions on the target, and receives events with the file path, number
+// of keys from each source, changes, and errors for loads, reloads, and
+// saves.  A nil logger restores detection on the target.
+func (c *Config) Logger(l *slog.Logger) {
+	c.mu.Lock()
+	c.slog = l
+	c.mu.Unlock()
+}
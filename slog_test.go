@@ -0,0 +1,65 @@
+package gonf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	modTime := time.Now()
+	data := `{"Secret": "first"}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+	createTemp = func(string, string) (*os.File, error) { return nil, mockError }
+	defer func() { createTemp = ioutil.TempFile }()
+
+	out := &bytes.Buffer{}
+	ml := &mockLogger{}
+	c := &Config{}
+	c.Target(ml)
+	c.Logger(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	c.Add("Public", "", "APP_PUBLIC", "--public")
+	c.Deprecated("Public", "", "--old")
+	os.Setenv("APP_PUBLIC", "env")
+	os.Args = []string{"app", "--old", "option"}
+	c.Load("/tmp/app.json")
+	for _, l := range []string{
+		`level=WARN msg="deprecated configuration used" used=--old replacement="--public (APP_PUBLIC)"`,
+		`level=INFO msg="configuration loaded" file=/tmp/app.json files=1 env=1 options=1`,
+	} {
+		if !strings.Contains(out.String(), l) {
+			t.Errorf("failed to log %s, %s...", l, out)
+		}
+	}
+
+	// test reload events and that the target logger is bypassed
+	out.Reset()
+	modTime, data = modTime.Add(time.Second), `{"Public": "file"}`
+	c.Reload()
+	if !strings.Contains(out.String(), `msg="configuration reloaded" file=/tmp/app.json changes=1`) || !strings.Contains(out.String(), `level=DEBUG msg="configuration changed" key=Public old=option new=file`) || ml.infos != 0 {
+		t.Errorf("failed to log reload, %s...", out)
+	}
+	out.Reset()
+	modTime, data = modTime.Add(time.Second), `not json`
+	c.Reload()
+	if !strings.Contains(out.String(), `level=ERROR msg="configuration reload failed"`) {
+		t.Errorf("failed to log reload failure, %s...", out)
+	}
+
+	// test save failure
+	out.Reset()
+	if c.Save() == nil || !strings.Contains(out.String(), `level=ERROR msg="configuration save failed" file=/tmp/app.json error=`) {
+		t.Errorf("failed to log save failure, %s...", out)
+	}
+	c.Logger(nil)
+	if c.event(slog.LevelInfo, "ignored") {
+		t.Error("failed to clear logger...")
+	}
+}
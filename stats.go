@@ -0,0 +1,63 @@
+package gonf
+
+import "time"
+
+// The time spent reading a single source during the last Load or Reload, with
+// the bytes read from files and the number of keys it supplied.
+type SourceStats struct {
+	Source   string        `json:"source"`
+	Duration time.Duration `json:"duration"`
+	Bytes    int64         `json:"bytes"`
+	Keys     int           `json:"keys"`
+}
+
+// The instrumentation of the last Load or Reload, with each source in the
+// order it was read.
+type Stats struct {
+	Reload   bool          `json:"reload"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Sources  []SourceStats `json:"sources"`
+}
+
+// Accumulates the stats of a single Load or Reload.
+type stopwatch struct {
+	c     *Config
+	start time.Time
+	stats Stats
+}
+
+func (c *Config) stopwatch(reload bool) *stopwatch {
+	return &stopwatch{c: c, start: time.Now(), stats: Stats{Reload: reload, Started: now()}}
+}
+
+// Time a source, counting the bytes read while it runs and the keys of the
+// values it returns.
+func (w *stopwatch) time(source string, fn func() map[string]interface{}) {
+	start, bytes := time.Now(), w.c.bytesRead.Load()
+	vars := fn()
+	w.stats.Sources = append(w.stats.Sources, SourceStats{
+		Source:   source,
+		Duration: time.Since(start),
+		Bytes:    w.c.bytesRead.Load() - bytes,
+		Keys:     len(w.c.flatten("", vars, map[string]interface{}{})),
+	})
+}
+
+func (w *stopwatch) stop() {
+	w.stats.Duration = time.Since(w.start)
+	w.c.mu.Lock()
+	w.c.stats = w.stats
+	w.c.mu.Unlock()
+}
+
+// Return the time spent reading each source during the last Load or Reload,
+// with the bytes read and the keys supplied, so that startup latency caused by
+// slow mounts, commands, or large files can be diagnosed.
+func (c *Config) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := c.stats
+	s.Sources = append([]SourceStats{}, s.Sources...)
+	return s
+}
@@ -0,0 +1,46 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	os.Args = []string{"--OptionString=option"}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	os.Setenv("ENV_STRING", "env")
+	data := `{"OptionNumber": 1, "ExplicitComposite": {"TripleDepth": "file"}}`
+	modTime := time.Now()
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Add("OptionString", "", "", "--OptionString")
+	c.Add("EnvString", "", "ENV_STRING")
+	c.Load("/tmp/app.json")
+	s := c.Stats()
+	counts := map[string]int{}
+	for _, source := range s.Sources {
+		counts[source.Source] = source.Keys
+	}
+	if s.Reload || s.Started.IsZero() || s.Duration <= 0 || len(s.Sources) != 5 || s.Sources[2].Source != "files" || s.Sources[2].Bytes != int64(len(data)) || counts["files"] != 2 || counts["options"] != 1 || counts["env"] != 1 {
+		t.Errorf("failed to record load stats, %+v...", s)
+	}
+
+	// test unchanged reloads keep the stats of the last load
+	c.Reload()
+	if c.Stats().Reload {
+		t.Errorf("failed to ignore unchanged reload, %+v...", c.Stats())
+	}
+
+	// test reloads replace the stats
+	data, modTime = `{"OptionNumber": 2}`, modTime.Add(time.Second)
+	c.Reload()
+	if s := c.Stats(); !s.Reload || len(s.Sources) != 2 || s.Sources[0].Bytes != int64(len(data)) || s.Sources[0].Keys != 1 {
+		t.Errorf("failed to record reload stats, %+v...", s)
+	}
+}
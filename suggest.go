@@ -0,0 +1,125 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func (c *Config) distance(a, b string) int {
+	x, y := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	row := make([]int, len(y)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			cur := row[j]
+			row[j] = min(row[j]+1, row[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return row[len(y)]
+}
+
+// Return the candidate closest to an unknown key, if it is near enough to be
+// a likely typo.
+func (c *Config) suggest(key string, candidates []string) string {
+	best, score := "", len(key)/3+1
+	for _, n := range candidates {
+		if d := c.distance(key, n); d > 0 && d <= score && (best == "" || d < score) {
+			best, score = n, d
+		}
+	}
+	return best
+}
+
+// Return the names of every setting and field, including those bound to a
+// prefix.
+func (c *Config) names() []string {
+	c.mu.RLock()
+	t, binds := reflect.TypeOf(c.target), append([]binding(nil), c.binds...)
+	names := make([]string, 0, len(c.settings))
+	for _, s := range c.settings {
+		names = append(names, s.Name)
+	}
+	c.mu.RUnlock()
+	names = append(names, c.fields(t, "")...)
+	for _, b := range binds {
+		names = append(names, c.fields(reflect.TypeOf(b.target), b.prefix)...)
+	}
+	return names
+}
+
+// Return the environment variables beginning with the EnvPrefix that are not
+// bound to any setting or field, along with those that are.
+func (c *Config) unboundEnvs() ([]string, []string) {
+	c.mu.RLock()
+	prefix, auto, t := c.envPrefix, c.autoEnv, reflect.TypeOf(c.target)
+	var known []string
+	for _, s := range c.settings {
+		if s.Env != "" {
+			known = append(known, s.Env)
+		}
+	}
+	c.mu.RUnlock()
+	if prefix == "" {
+		return nil, nil
+	}
+	if auto {
+		for _, n := range c.fields(t, "") {
+			known = append(known, c.envName(n))
+		}
+	}
+	var unbound []string
+	for _, e := range os.Environ() {
+		n := strings.SplitN(e, "=", 2)[0]
+		if strings.HasPrefix(n, strings.ToUpper(prefix)+"_") && !c.contains(known, n) {
+			unbound = append(unbound, n)
+		}
+	}
+	sort.Strings(unbound)
+	return unbound, known
+}
+
+func (c *Config) unknownEnvs() error {
+	unbound, known := c.unboundEnvs()
+	var errs []error
+	for _, n := range unbound {
+		hint := ""
+		if s := c.suggest(n, known); s != "" {
+			hint = fmt.Sprintf(", did you mean %s?", s)
+		}
+		c.notify(slog.LevelWarn, "unknown environment variable %s%s", n, hint)
+		errs = append(errs, fmt.Errorf("%w (%s%s)", ErrUnknownKey, n, hint))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// When enabled, keys in configuration files and environment variables with
+// the EnvPrefix that match nothing are returned as errors by Load, instead of
+// only being logged as warnings.  The --check-config option is always strict.
+func (c *Config) Strict(enabled bool) {
+	c.mu.Lock()
+	c.strict = enabled
+	c.mu.Unlock()
+}
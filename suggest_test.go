@@ -0,0 +1,48 @@
+package gonf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(`{"OptionNumbr": 1, "Zzz": true}`), nil }
+	os.Setenv("APP_NUMBR", "2")
+	os.Setenv("OTHER", "3")
+
+	ml := &mockLevels{}
+	c := &Config{}
+	c.Target(ml)
+	c.EnvPrefix("app")
+	c.Add("OptionNumber", "", "APP_NUMBER")
+
+	// test warnings without errors by default
+	if e := c.Load("/tmp/app.json"); e != nil {
+		t.Errorf("failed to ignore unknown keys outside strict mode, %v...", e)
+	}
+	warnings := strings.Join(ml.warnings, "\n")
+	for _, w := range []string{"OptionNumbr in /tmp/app.json, did you mean OptionNumber?", "environment variable APP_NUMBR, did you mean APP_NUMBER?", "key Zzz in /tmp/app.json\n"} {
+		if !strings.Contains(warnings+"\n", w) {
+			t.Errorf("failed to warn %s, %s...", w, warnings)
+		}
+	}
+	if strings.Contains(warnings, "OTHER") {
+		t.Error("failed to ignore environment variables without the prefix...")
+	}
+
+	// test errors in strict mode
+	c.Strict(true)
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, ErrUnknownKey) || !strings.Contains(e.Error(), "did you mean") {
+		t.Errorf("failed to report unknown keys in strict mode, %v...", e)
+	}
+
+	if d := c.distance("kitten", "sitting"); d != 3 {
+		t.Errorf("failed to measure distance, %d...", d)
+	}
+}
@@ -0,0 +1,63 @@
+package gonf
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Boxes the current configuration so that atomic.Value always stores the
+// same concrete type.
+type current struct {
+	value interface{}
+}
+
+func (c *Config) value() interface{} {
+	if v, ok := c.current.Load().(current); ok && v.value != nil {
+		return v.value
+	}
+	return c.target
+}
+
+// Marshal the current configuration while the lock is held, respecting a
+// target or Locker that supports locking.
+func (c *Config) marshal() []byte {
+	v := c.value()
+	if v == nil {
+		return nil
+	}
+	defer c.readLock(v)()
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func (c *Config) fresh() (interface{}, error) {
+	t := reflect.TypeOf(c.target)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, errNotSwappable
+	}
+	v := reflect.New(t.Elem()).Interface()
+	return v, json.Unmarshal(c.marshal(), v)
+}
+
+// When enabled, Load, Reload, and Apply build a fresh copy of the target,
+// apply and validate the configuration against it, and only then expose it
+// through Current, so that readers never observe a half-updated
+// configuration.  The target supplied is left untouched after the first
+// swap, and must be a pointer to a struct.
+func (c *Config) Swap(enabled bool) {
+	c.mu.Lock()
+	c.swap = enabled
+	c.mu.Unlock()
+}
+
+// Return the latest configuration, which is the target unless Swap has been
+// enabled, in which case it is the most recently applied copy.  Once a copy
+// has been applied this never blocks, so it is safe to call from hot paths.
+func (c *Config) Current() interface{} {
+	if v, ok := c.current.Load().(current); ok && v.value != nil {
+		return v.value
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.target
+}
@@ -0,0 +1,54 @@
+package gonf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSwap(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	modTime := time.Now()
+	data := `{"OptionString": "first", "EnvNumber": 1}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(data), nil }
+
+	mc := &mockConfig{OptionBool: true}
+	c := &Config{}
+	c.Target(mc)
+	if c.Current() != mc {
+		t.Error("failed to return target before swapping...")
+	}
+	c.Swap(true)
+	c.Load("/tmp/app.json")
+	first, ok := c.Current().(*mockConfig)
+	if !ok || first == mc || first.OptionString != "first" || !first.OptionBool || mc.OptionString != "" {
+		t.Fatalf("failed to swap a fresh copy, %+v %+v...", first, mc)
+	}
+
+	// test reload swaps again without modifying the previous copy
+	modTime, data = modTime.Add(time.Second), `{"OptionString": "second"}`
+	c.Reload()
+	if second := c.Current().(*mockConfig); second == first || second.OptionString != "second" || second.EnvNumber != 1 || first.OptionString != "first" {
+		t.Errorf("failed to swap on reload, %+v %+v...", first, second)
+	}
+	if v, _ := c.Get("OptionString"); v != "second" {
+		t.Errorf("failed to read current configuration, %v...", v)
+	}
+
+	// test failures are not exposed
+	before := c.Current()
+	modTime, data = modTime.Add(time.Second), `{"OptionString": 5}`
+	if c.Reload() == nil || c.Current() != before {
+		t.Error("failed to keep the previous configuration after a failure...")
+	}
+
+	// test unsupported targets
+	c = &Config{}
+	c.Target(&[]string{})
+	c.Swap(true)
+	if c.to(map[string]interface{}{}) != errNotSwappable {
+		t.Error("failed to reject unsupported target...")
+	}
+}
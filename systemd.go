@@ -0,0 +1,55 @@
+package gonf
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+var sdNotify = func(socket, state string) error {
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// Return the files in each directory of `$CONFIGURATION_DIRECTORY`, which
+// systemd sets from ConfigurationDirectory=, named after each relative file.
+func (c *Config) configurationDirectory(files []string) []string {
+	var list []string
+	for _, d := range filepath.SplitList(os.Getenv("CONFIGURATION_DIRECTORY")) {
+		for _, f := range files {
+			if d != "" && !filepath.IsAbs(f) {
+				list = append(list, filepath.Join(d, filepath.Base(f)))
+			}
+		}
+	}
+	return list
+}
+
+// Notify systemd of a state change when started with `$NOTIFY_SOCKET`.
+func (c *Config) daemon(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	if err := sdNotify(socket, state); err != nil {
+		c.notify(slog.LevelWarn, "unable to notify systemd: %v", err)
+	}
+}
+
+func (c *Config) reloading() {
+	state := "RELOADING=1"
+	if usec, ok := monotonic(); ok {
+		state += fmt.Sprintf("\nMONOTONIC_USEC=%d", usec)
+	}
+	c.daemon(state)
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package gonf
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Return CLOCK_MONOTONIC in microseconds, as systemd expects with RELOADING.
+func monotonic() (int64, bool) {
+	var ts syscall.Timespec
+	if _, _, e := syscall.Syscall(syscall.SYS_CLOCK_GETTIME, 1, uintptr(unsafe.Pointer(&ts)), 0); e != 0 {
+		return 0, false
+	}
+	return ts.Nano() / 1000, true
+}
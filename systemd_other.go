@@ -0,0 +1,7 @@
+//go:build !linux
+
+package gonf
+
+func monotonic() (int64, bool) {
+	return 0, false
+}
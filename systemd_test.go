@@ -0,0 +1,93 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSystemd(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	os.Setenv("CREDENTIALS_DIRECTORY", "/run/credentials/app.service")
+	os.Setenv("CONFIGURATION_DIRECTORY", "/etc/app")
+	os.Setenv("NOTIFY_SOCKET", "@notify")
+	modTime := time.Now()
+	data := `{"OptionString": "credential://token", "EnvString": "credential://../escape"}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: modTime}, nil }
+	readfile = func(n string) ([]byte, error) {
+		switch n {
+		case filepath.Join("/etc", "app", "app.json"):
+			return []byte(data), nil
+		case filepath.Join("/run/credentials/app.service", "token"):
+			return []byte("secret\n"), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	var states []string
+	defer func(fn func(string, string) error) { sdNotify = fn }(sdNotify)
+	sdNotify = func(socket, state string) error {
+		if socket == "@notify" {
+			states = append(states, state)
+		}
+		return nil
+	}
+
+	// test credentials and the configuration directory
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Name("app")
+	c.References(true)
+	if f := c.defaultFiles(); len(f) < 2 || f[0] != filepath.Join("/etc", "app", "app.json") || f[1] != filepath.Join("app", "app.json") {
+		t.Errorf("failed to search the configuration directory first, %v...", f)
+	}
+	if e := c.Load(); e == nil || mc.OptionString != "secret" || c.ConfigFile() != filepath.Join("/etc", "app", "app.json") {
+		t.Errorf("failed to read credential or configuration directory, %v %s %s...", e, mc.OptionString, c.ConfigFile())
+	}
+
+	// test reloads notify systemd unless nothing changed
+	c.Reload()
+	if len(states) != 0 {
+		t.Errorf("failed to skip notifying unchanged reloads, %v...", states)
+	}
+	data, modTime = `{"OptionString": "changed"}`, modTime.Add(time.Second)
+	if e := c.Reload(); e != nil || len(states) != 2 || !strings.HasPrefix(states[0], "RELOADING=1") || states[1] != "READY=1" {
+		t.Errorf("failed to notify systemd around reload, %v %q...", e, states)
+	}
+	if runtime.GOOS == "linux" && !strings.Contains(states[0], "\nMONOTONIC_USEC=") {
+		t.Errorf("failed to supply the monotonic clock, %q...", states[0])
+	}
+}
+
+func TestSdNotify(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("notify sockets are only supported on linux...")
+	}
+	d, e := ioutil.TempDir(os.TempDir(), "gonf")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(d)
+	socket := filepath.Join(d, "notify")
+	conn, e := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer conn.Close()
+	if e := sdNotify(socket, "READY=1"); e != nil {
+		t.Fatalf("failed to notify socket, %v...", e)
+	}
+	b := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if n, _ := conn.Read(b); string(b[:n]) != "READY=1" {
+		t.Errorf("failed to receive state, %q...", b[:n])
+	}
+}
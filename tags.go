@@ -0,0 +1,158 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A setting declared by a `gonf:"..."` struct tag on the target.
+type spec struct {
+	setting
+	Default  *string
+	Required bool
+}
+
+func (c *Config) specs(t reflect.Type, prefix string) ([]spec, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || strings.Count(prefix, ".") > maxDepth {
+		return nil, nil
+	}
+	var list []spec
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := strings.Split(f.Tag.Get("json"), ",")[0]
+		if n == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		} else if n == "" && f.Anonymous {
+			l, err := c.specs(f.Type, prefix)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, l...)
+			continue
+		} else if n == "" {
+			n = f.Name
+		}
+		s := spec{}
+		for _, o := range strings.Split(f.Tag.Get("gonf"), ",") {
+			kv := strings.SplitN(strings.TrimSpace(o), "=", 2)
+			switch {
+			case len(kv) == 1 && kv[0] == "required":
+				s.Required = true
+			case len(kv) == 2 && kv[0] == "name":
+				c.mu.RLock()
+				folded := c.keyCase != "" && c.fold(kv[1]) == c.fold(n)
+				c.mu.RUnlock()
+				if !strings.EqualFold(kv[1], n) && !folded {
+					return nil, fmt.Errorf("%w (%s: %s)", errBadTag, f.Name, kv[1])
+				}
+				n = kv[1]
+			case len(kv) == 2 && kv[0] == "env":
+				s.Env = kv[1]
+			case len(kv) == 2 && kv[0] == "flag":
+				s.Options = append(s.Options, kv[1])
+			case len(kv) == 2 && kv[0] == "desc":
+				s.Description = kv[1]
+			case len(kv) == 2 && kv[0] == "default":
+				d := kv[1]
+				s.Default = &d
+			}
+		}
+		if prefix != "" {
+			n = prefix + "." + n
+		}
+		if s.Name = n; s.Env != "" || len(s.Options) > 0 || s.Default != nil || s.Required || s.Description != "" {
+			list = append(list, s)
+		}
+		l, err := c.specs(f.Type, n)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, l...)
+	}
+	return list, nil
+}
+
+// Register the settings declared by struct tags on the target, unless they
+// have already been registered with Add, and collect their defaults.
+func (c *Config) harvest() (map[string]interface{}, error) {
+	c.mu.RLock()
+	t := reflect.TypeOf(c.target)
+	c.mu.RUnlock()
+	list, err := c.specs(t, "")
+	errs := []error{err}
+	defaults := map[string]interface{}{}
+	descriptions := map[string]string{}
+	for _, s := range list {
+		descriptions[s.Name] = s.Description
+		if s.Env != "" || len(s.Options) > 0 {
+			c.mu.RLock()
+			exists := c.setting(s.Name) != nil
+			c.mu.RUnlock()
+			if !exists {
+				errs = append(errs, c.Add(s.Name, s.Description, s.Env, s.Options...))
+			}
+		}
+		if s.Required {
+			c.mu.Lock()
+			if c.required == nil {
+				c.required = map[string]struct{}{}
+			}
+			c.required[s.Name] = struct{}{}
+			c.mu.Unlock()
+		}
+		if s.Default != nil {
+			c.set(defaults, s.Name, *s.Default)
+		}
+	}
+	errs = append(errs, c.flags(t, descriptions))
+	return defaults, errors.Join(errs...)
+}
+
+func (c *Config) missing(data ...map[string]interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var names []string
+	for n := range c.required {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, n := range names {
+		found := false
+		for _, m := range data {
+			if v, ok := c.lookup(m, n); ok && v != nil {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%w (%s)", ErrRequired, n))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Require registered names to be supplied by a file, environment variable,
+// or command line option, otherwise Load reports ErrRequired.  Properties
+// may also be marked with a `gonf:"required"` struct tag.
+func (c *Config) Required(names ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range names {
+		if c.setting(n) == nil {
+			return errNotRegistered
+		}
+	}
+	if c.required == nil {
+		c.required = map[string]struct{}{}
+	}
+	for _, n := range names {
+		c.required[n] = struct{}{}
+	}
+	return nil
+}
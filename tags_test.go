@@ -0,0 +1,62 @@
+package gonf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type mockTagged struct {
+	Port    int    `json:"port" gonf:"name=port,env=PORT,flag=-p,flag=--port,default=8080,desc=listen port,required"`
+	Host    string `gonf:"env=HOST,default=localhost"`
+	Token   string `gonf:"env=TOKEN,sensitive"`
+	Nothing string
+	Server  struct {
+		Name string `gonf:"flag=--server-name"`
+	}
+}
+
+type mockBadTag struct {
+	Port int `json:"port" gonf:"name=other,env=PORT"`
+}
+
+func TestTags(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return []byte(`{}`), nil }
+
+	// test defaults and required without any sources
+	os.Args = []string{"app"}
+	mt := &mockTagged{}
+	c := &Config{}
+	c.Target(mt)
+	c.Add("Host", "custom description", "APP_HOST")
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, ErrRequired) || mt.Port != 8080 || mt.Host != "localhost" {
+		t.Errorf("failed to apply defaults or report required settings, %v %+v...", e, mt)
+	}
+	if s := c.setting("port"); s == nil || s.Env != "PORT" || strings.Join(s.Options, " ") != "-p --port" || s.Description != "listen port" {
+		t.Errorf("failed to register tagged setting, %+v...", s)
+	}
+	if s := c.setting("Host"); s == nil || s.Env != "APP_HOST" || c.setting("Nothing") != nil || c.setting("Server.Name") == nil || !c.isSensitive("Token") {
+		t.Error("failed to respect existing registrations, untagged fields, nesting, or sensitivity...")
+	}
+
+	// test supplied values
+	os.Args = []string{"app", "-p", "9090", "--server-name", "web"}
+	os.Setenv("TOKEN", "secret")
+	if e := c.Load("/tmp/app.json"); e != nil || mt.Port != 9090 || mt.Server.Name != "web" || mt.Token != "secret" {
+		t.Errorf("failed to load tagged settings, %v %+v...", e, mt)
+	}
+
+	// test bad tags and the required function
+	c = &Config{}
+	c.Target(&mockBadTag{})
+	if e := c.Load("/tmp/app.json"); !errors.Is(e, errBadTag) {
+		t.Errorf("failed to reject mismatched name, %v...", e)
+	}
+	if c.Required("missing") == nil {
+		t.Error("failed to reject unregistered required name...")
+	}
+}
@@ -0,0 +1,87 @@
+package gonf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var errBadTemplate = errors.New("unable to expand template...")
+
+func (c *Config) template(path, s string, data map[string]interface{}) (string, error) {
+	t, err := template.New(path).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	b := &bytes.Buffer{}
+	err = t.Execute(b, data)
+	return b.String(), err
+}
+
+func (c *Config) interpolate(prefix string, in, data map[string]interface{}, refs map[string]reference) (map[string]interface{}, error) {
+	var errs []error
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			m, err := c.interpolate(path, t, data, refs)
+			out[k], errs = m, append(errs, err)
+		case string:
+			if !strings.Contains(t, "{{") {
+				out[k] = v
+			} else if s, err := c.template(path, t, data); err != nil {
+				out[k], errs = v, append(errs, fmt.Errorf("%s: %w %v", path, errBadTemplate, err))
+			} else {
+				out[k], refs[path] = s, reference{ref: t, value: s}
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// Expand templates in the string values of each layer against the base merged
+// with every layer, and remember the templates so that Save restores them.
+func (c *Config) expand(base map[string]interface{}, layers ...map[string]interface{}) ([]map[string]interface{}, error) {
+	c.mu.RLock()
+	enabled := c.templates
+	c.mu.RUnlock()
+	if !enabled {
+		return layers, nil
+	}
+	data := c.merge(append([]map[string]interface{}{base}, layers...)...)
+	refs := map[string]reference{}
+	var errs []error
+	out := make([]map[string]interface{}, len(layers))
+	for i, l := range layers {
+		var err error
+		out[i], err = c.interpolate("", l, data, refs)
+		errs = append(errs, err)
+	}
+	c.mu.Lock()
+	if c.references == nil {
+		c.references = map[string]reference{}
+	}
+	for k, r := range refs {
+		c.references[k] = r
+	}
+	c.mu.Unlock()
+	return out, errors.Join(errs...)
+}
+
+// Enable expansion of `{{ .server.host }}` style templates inside string
+// values, which are resolved against the configuration merged from every
+// source, so that derived values such as a url need not repeat the host and
+// port.  Templates are expanded once, so a template may not refer to another.
+func (c *Config) Templates(enabled bool) {
+	c.mu.Lock()
+	c.templates = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,53 @@
+package gonf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplates(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	file := `{"OptionString": "http://{{ .EnvString }}:{{ .OptionNumber }}/", "EnvString": "localhost", "OptionNumber": 8080}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(file), nil }
+
+	// test templates are left alone by default
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "http://{{ .EnvString }}:{{ .OptionNumber }}/" {
+		t.Errorf("failed to leave templates by default, %v %s...", e, mc.OptionString)
+	}
+
+	// test templates are expanded against every source
+	os.Args = []string{"--EnvString=example.com"}
+	c = &Config{}
+	c.Target(mc)
+	c.Add("EnvString", "", "", "--EnvString")
+	c.Templates(true)
+	if e := c.Load("/tmp/app.json"); e != nil || mc.OptionString != "http://example.com:8080/" {
+		t.Errorf("failed to expand templates, %v %s...", e, mc.OptionString)
+	}
+
+	// test saving restores unchanged templates
+	data, _ := c.encode("/tmp/app.json")
+	if !strings.Contains(string(data), "{{ .EnvString }}") {
+		t.Errorf("failed to restore templates on save, %s...", data)
+	}
+
+	// test missing keys are reported on reload
+	os.Args = []string{}
+	file = `{"OptionString": "{{ .Missing }}"}`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	if e := c.Reload(); !errors.Is(e, errBadTemplate) {
+		t.Errorf("failed to report missing template key, %v...", e)
+	}
+}
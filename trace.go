@@ -0,0 +1,107 @@
+package gonf
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+)
+
+var stderr io.Writer = os.Stderr
+
+// Report whether tracing was enabled by Debug or the GONF_DEBUG environment
+// variable, while the lock is held.
+func (c *Config) tracing() bool {
+	if c.debug {
+		return true
+	}
+	b, _ := strconv.ParseBool(os.Getenv("GONF_DEBUG"))
+	return b
+}
+
+// Write a trace line through the Logger or the target at debug level, or
+// otherwise to standard error.
+func (c *Config) trace(format string, args ...interface{}) {
+	c.mu.RLock()
+	enabled, s, t := c.tracing(), c.slog, c.target
+	c.mu.RUnlock()
+	if !enabled {
+		return
+	} else if _, ok := t.(logger); ok || s != nil {
+		c.notify(slog.LevelDebug, format, args...)
+		return
+	}
+	fmt.Fprintf(stderr, "gonf: "+format+"\n", args...)
+}
+
+// Record a trace line while the lock is held, to be written once it has been
+// released.
+func (c *Config) traced(format string, args ...interface{}) {
+	if c.tracing() {
+		c.traces = append(c.traces, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *Config) consulted(name string, err error) {
+	if err != nil {
+		c.trace("consulted %s: %v", name, err)
+	} else {
+		c.trace("consulted %s: found", name)
+	}
+}
+
+// Trace the keys supplied by each source, from lowest to highest precedence,
+// and the source that won each key.
+func (c *Config) explain(defaults map[string]interface{}) {
+	c.mu.RLock()
+	enabled := c.tracing()
+	merged := c.flatten("", c.merged, map[string]interface{}{})
+	c.mu.RUnlock()
+	if !enabled {
+		return
+	}
+	var keys []string
+	for k := range c.flatten("", defaults, map[string]interface{}{}) {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c.trace("default supplied %s", k)
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		var lines []string
+		c.mu.RLock()
+		for k, o := range c.sources[layers[i]] {
+			lines = append(lines, fmt.Sprintf("%s supplied %s", o, k))
+		}
+		c.mu.RUnlock()
+		sort.Strings(lines)
+		for _, l := range lines {
+			c.trace("%s", l)
+		}
+	}
+	keys = keys[:0]
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := merged[k]
+		if c.isSensitive(k) {
+			v = redacted
+		}
+		c.trace("%s = %v from %s", k, v, c.source(k))
+	}
+}
+
+// Trace each source consulted, the keys each source supplied, every string
+// converted to another type, and the source that won each key during Load
+// and Reload, to answer questions of precedence.  It may also be enabled by
+// setting the GONF_DEBUG environment variable to true.
+func (c *Config) Debug(enabled bool) {
+	c.mu.Lock()
+	c.debug = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,64 @@
+package gonf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebug(t *testing.T) {
+	os.Args = []string{"--OptionString=option"}
+	os.Clearenv()
+	defer func() { stat, readfile, stderr = os.Stat, ioutil.ReadFile, os.Stderr }()
+	os.Setenv("ENV_NUMBER", "5")
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(`{"OptionString": "file", "OptionNumber": 1}`), nil }
+	b := &bytes.Buffer{}
+	stderr = b
+
+	// test nothing is traced by default
+	c := &Config{}
+	c.Target(&mockConfig{})
+	c.Add("OptionString", "", "", "--OptionString")
+	c.Add("EnvNumber", "", "ENV_NUMBER")
+	c.Load("/tmp/app.json")
+	if b.Len() > 0 {
+		t.Errorf("failed to disable tracing by default, %s...", b)
+	}
+
+	// test the environment enables tracing
+	os.Setenv("GONF_DEBUG", "true")
+	c.Load("/tmp/app.json")
+	out := b.String()
+	for _, l := range []string{
+		"gonf: consulted /tmp/app.json: found\n",
+		"gonf: /tmp/app.json supplied OptionNumber\n",
+		"gonf: env ENV_NUMBER supplied EnvNumber\n",
+		`gonf: converted "5" to int (EnvNumber)` + "\n",
+		"gonf: OptionString = option from option --OptionString\n",
+		"gonf: OptionNumber = 1 from /tmp/app.json\n",
+	} {
+		if !strings.Contains(out, l) {
+			t.Errorf("failed to trace %q in %s...", l, out)
+		}
+	}
+
+	// test the option enables tracing
+	os.Unsetenv("GONF_DEBUG")
+	b.Reset()
+	c.Debug(true)
+	c.Load("/tmp/app.json")
+	if !strings.Contains(b.String(), "gonf: consulted /tmp/app.json: found\n") {
+		t.Errorf("failed to enable tracing, %s...", b)
+	}
+	// test sensitive conversions are redacted
+	b.Reset()
+	c.Sensitive("EnvNumber")
+	c.Load("/tmp/app.json")
+	if out := b.String(); strings.Contains(out, `"5"`) || !strings.Contains(out, `converted "***" to int (EnvNumber)`) {
+		t.Errorf("failed to redact sensitive conversions, %s...", out)
+	}
+}
@@ -0,0 +1,84 @@
+package gonf
+
+import (
+	"errors"
+	"reflect"
+)
+
+// A target that checks its own consistency once configuration is applied.
+type validator interface {
+	Validate() error
+}
+
+// Copy the fields that encoding/json sees from a struct, leaving unexported
+// and ignored fields, including any embedded lock, untouched.
+func (c *Config) copyFields(dst, src reflect.Value) {
+	for _, f := range c.jsonFields(dst.Type(), nil, 0) {
+		d, s := dst, src
+		for i, n := range f.index {
+			if i > 0 && d.Kind() == reflect.Ptr {
+				if s.IsNil() {
+					break
+				} else if d.IsNil() {
+					d.Set(reflect.New(d.Type().Elem()))
+				}
+				d, s = d.Elem(), s.Elem()
+			}
+			d, s = d.Field(n), s.Field(n)
+		}
+		if d.CanSet() {
+			d.Set(s)
+		}
+	}
+}
+
+// Apply a configuration to copies of the target and every bound target and
+// validate them, then copy them back, or expose the target copy when Swap is
+// enabled, so that no target is ever left partially updated.
+func (c *Config) commit(vars map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.target == nil {
+		return errNilTarget
+	}
+	candidate, err := c.fresh()
+	if err != nil {
+		return err
+	}
+	combo := c.merge(vars)
+	staged, errs := c.stage(combo)
+	if errs = append(errs, c.apply(candidate, combo)...); len(errs) > 0 {
+		return errors.Join(errs...)
+	} else if v, ok := candidate.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.swap {
+		c.current.Store(current{candidate})
+	} else {
+		unlock := c.writeLock(c.target)
+		c.copyFields(reflect.ValueOf(c.target).Elem(), reflect.ValueOf(candidate).Elem())
+		unlock()
+	}
+	for i, s := range staged {
+		if s != nil {
+			unlock := c.writeLock(c.binds[i].target)
+			c.copyFields(reflect.ValueOf(c.binds[i].target).Elem(), reflect.ValueOf(s).Elem())
+			unlock()
+		}
+	}
+	return nil
+}
+
+// When enabled, Reload applies changes to a copy of the target, and only
+// copies it back once every file was read and the copy passes validation,
+// including its own Validate function if it has one.  Otherwise the previous
+// configuration is kept, no callbacks are run, and the error is returned, so
+// that a bad configuration push never takes down a healthy service.  The
+// target must be a pointer to a struct.
+func (c *Config) Transactional(enabled bool) {
+	c.mu.Lock()
+	c.transactional = enabled
+	c.mu.Unlock()
+}
@@ -0,0 +1,69 @@
+package gonf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+)
+
+type mockTransaction struct {
+	sync.Mutex
+	Port    int
+	Name    string
+	Ignored string `json:"-"`
+}
+
+func (m *mockTransaction) Validate() error {
+	if m.Port > 65535 {
+		return mockError
+	}
+	return nil
+}
+
+func TestTransactional(t *testing.T) {
+	data := []byte(`{"Port": 80, "Name": "web"}`)
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	stat = func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+	readfile = func(string) ([]byte, error) { return data, nil }
+
+	mt := &mockTransaction{Port: 1, Ignored: "kept"}
+	c := &Config{configFile: "test.gonf.json"}
+	c.Target(mt)
+	c.Transactional(true)
+	var reloads int
+	c.OnReload(func(ChangeSet) { reloads++ })
+
+	// test a valid reload is committed
+	if e := c.Reload(); e != nil || mt.Port != 80 || mt.Name != "web" || mt.Ignored != "kept" || reloads != 1 {
+		t.Errorf("failed to commit reload, %v %+v...", e, mt)
+	}
+
+	// test failed validation and conversion keep the previous configuration
+	for d, expected := range map[string]error{`{"Port": 70000, "Name": "invalid"}`: mockError, `{"Port": "many", "Name": "invalid"}`: ErrCast} {
+		data = []byte(d)
+		if e := c.Reload(); !errors.Is(e, expected) || mt.Port != 80 || mt.Name != "web" || reloads != 1 {
+			t.Errorf("failed to roll back reload, %v %+v...", e, mt)
+		}
+	}
+
+	// test a failed binding keeps the target and the bound target unchanged
+	mb := &mockTransaction{Port: 5}
+	c.Bind("db", mb)
+	data = []byte(`{"Port": 90, "db": {"Port": "many"}}`)
+	if e := c.Reload(); !errors.Is(e, ErrCast) || mt.Port != 80 || mb.Port != 5 || reloads != 1 {
+		t.Errorf("failed to roll back a failed binding, %v %+v %+v...", e, mt, mb)
+	}
+	data = []byte(`{"Port": 80, "Name": "web", "db": {"Port": 6}}`)
+	if e := c.Reload(); e != nil || mt.Port != 80 || mb.Port != 6 || reloads != 2 {
+		t.Errorf("failed to commit binding, %v %+v %+v...", e, mt, mb)
+	}
+
+	// test swapped copies are committed
+	c.Swap(true)
+	data = []byte(`{"Port": 443}`)
+	if e := c.Reload(); e != nil || c.Current().(*mockTransaction).Port != 443 || mt.Port != 80 {
+		t.Errorf("failed to commit swapped reload, %v...", e)
+	}
+}
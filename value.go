@@ -0,0 +1,52 @@
+package gonf
+
+import "sync/atomic"
+
+// A dot-notation key whose latest value is replaced atomically after each
+// Load, Reload, Apply, or Set, so that long-lived goroutines may read it
+// without holding a lock or reading the target.
+type Value[T any] struct {
+	c   *Config
+	key string
+	v   atomic.Pointer[T]
+}
+
+// Bind a Value to a dot-notation key of the configuration, which holds the
+// zero value of T until the key is supplied.  A value that cannot be converted
+// to T leaves the previous value in place.
+func NewValue[T any](c *Config, key string) *Value[T] {
+	v := &Value[T]{c: c, key: key}
+	v.refresh()
+	c.mu.Lock()
+	c.values = append(c.values, v.refresh)
+	c.mu.Unlock()
+	return v
+}
+
+func (v *Value[T]) refresh() {
+	var t T
+	if raw, ok := v.c.Get(v.key); ok && raw != nil {
+		if err := Cast(raw, &t); err != nil {
+			return
+		}
+	}
+	v.v.Store(&t)
+}
+
+// Return the latest value of the key.
+func (v *Value[T]) Load() T {
+	if p := v.v.Load(); p != nil {
+		return *p
+	}
+	var t T
+	return t
+}
+
+func (c *Config) refresh() {
+	c.mu.RLock()
+	values := c.values
+	c.mu.RUnlock()
+	for _, fn := range values {
+		fn()
+	}
+}
@@ -0,0 +1,53 @@
+package gonf
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValue(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { stat, readfile = os.Stat, ioutil.ReadFile }()
+	file := `{"OptionNumber": 5, "plugin": {"port": 80}}`
+	stat = func(string) (os.FileInfo, error) { return &mockStat{modTime: time.Now()}, nil }
+	readfile = func(string) ([]byte, error) { return []byte(file), nil }
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	number, port := NewValue[int](c, "OptionNumber"), NewValue[int](c, "plugin.port")
+	if number.Load() != 0 || port.Load() != 0 {
+		t.Errorf("failed to start with zero values, %d %d...", number.Load(), port.Load())
+	}
+	if e := c.Load("/tmp/app.json"); e != nil || number.Load() != 5 || port.Load() != 80 {
+		t.Errorf("failed to bind values on load, %v %d %d...", e, number.Load(), port.Load())
+	}
+
+	// test values follow changes while being read concurrently
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			number.Load()
+		}
+	}()
+	file = `{"OptionNumber": 7, "plugin": {"port": 8080}}`
+	c.mu.Lock()
+	c.configModified = time.Time{}
+	c.mu.Unlock()
+	if e := c.Reload(); e != nil || number.Load() != 7 || port.Load() != 8080 {
+		t.Errorf("failed to update values on reload, %v %d %d...", e, number.Load(), port.Load())
+	}
+	wg.Wait()
+
+	// test invalid values keep the previous value
+	c.Set("plugin.port", "forever")
+	if port.Load() != 8080 {
+		t.Errorf("failed to keep previous value, %d...", port.Load())
+	}
+}
@@ -0,0 +1,77 @@
+package gonf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errNilVariant = errors.New("a variant requires a name and a value of its type...")
+
+// A concrete type registered for interface fields, chosen by name.
+type variant struct {
+	name string
+	t    reflect.Type
+}
+
+// Populate an interface field from an object whose discriminator names a
+// registered Variant implementing it while the lock is held, reporting
+// whether the object had a discriminator.
+func (c *Config) variant(d reflect.Value, v interface{}, path string) ([]error, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(c.variants) == 0 {
+		return nil, false
+	}
+	key := c.discriminator
+	if key == "" {
+		key = "type"
+	}
+	name, ok := m[key].(string)
+	if !ok {
+		return nil, false
+	}
+	for _, vt := range c.variants {
+		if vt.name != name {
+			continue
+		}
+		p := reflect.New(vt.t)
+		if p.Type().Implements(d.Type()) {
+			errs := c.populate(p.Elem(), m, path)
+			d.Set(p)
+			return errs, true
+		} else if vt.t.Implements(d.Type()) {
+			errs := c.populate(p.Elem(), m, path)
+			d.Set(p.Elem())
+			return errs, true
+		}
+	}
+	return []error{fmt.Errorf("%w unknown %s %q into %s (%s)", ErrCast, key, name, d.Type(), path)}, true
+}
+
+// Register a concrete type, by a value or pointer of it, for interface fields
+// of the target, which is chosen when the object supplied for such a field has
+// a discriminator key of "type", or that set by Discriminator, with the name
+// (eg. `{"type": "s3", "bucket": "logs"}`), for pluggable backends.  The same
+// name may be registered for types implementing different interfaces.  A
+// pointer is stored in the field when the pointer implements the interface.
+func (c *Config) Variant(name string, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if name == "" || t == nil {
+		return errNilVariant
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	c.mu.Lock()
+	c.variants = append(c.variants, variant{name, t})
+	c.mu.Unlock()
+	return nil
+}
+
+// Set the key of objects that names the Variant for an interface field, which
+// is "type" by default.
+func (c *Config) Discriminator(key string) {
+	c.mu.Lock()
+	c.discriminator = key
+	c.mu.Unlock()
+}
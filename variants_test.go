@@ -0,0 +1,58 @@
+package gonf
+
+import (
+	"errors"
+	"testing"
+)
+
+type mockStorage interface {
+	Location() string
+}
+
+type mockDisk struct {
+	Path string
+}
+
+func (d mockDisk) Location() string { return d.Path }
+
+type mockBucket struct {
+	Kind   string `json:"kind"`
+	Bucket string
+}
+
+func (b *mockBucket) Location() string { return "s3://" + b.Bucket }
+
+type mockBackends struct {
+	Storage  mockStorage
+	Replicas []mockStorage
+	Extra    interface{}
+}
+
+func TestVariant(t *testing.T) {
+	m := &mockBackends{}
+	c := &Config{}
+	c.Target(m)
+	if c.Variant("", &mockDisk{}) == nil || c.Variant("disk", nil) == nil {
+		t.Error("failed to reject variant without name or type...")
+	}
+	c.Variant("disk", mockDisk{})
+	c.Variant("s3", &mockBucket{})
+	c.Discriminator("kind")
+	if e := c.Apply(map[string]interface{}{
+		"Storage":  map[string]interface{}{"kind": "s3", "Bucket": "logs"},
+		"Replicas": []interface{}{map[string]interface{}{"kind": "disk", "Path": "/var/backup"}},
+		"Extra":    map[string]interface{}{"kind": "disk"},
+	}); e != nil || m.Storage == nil || m.Storage.Location() != "s3://logs" || len(m.Replicas) != 1 || m.Replicas[0].Location() != "/var/backup" {
+		t.Errorf("failed to populate interface fields by discriminator, %v %+v...", e, m)
+	}
+	if b, ok := m.Storage.(*mockBucket); !ok || b.Kind != "s3" {
+		t.Errorf("failed to store pointer variant, %#v...", m.Storage)
+	} else if _, ok := m.Extra.(map[string]interface{}); !ok {
+		t.Errorf("failed to leave empty interfaces alone, %#v...", m.Extra)
+	}
+
+	// test unknown names are rejected
+	if e := c.Apply(map[string]interface{}{"Storage": map[string]interface{}{"kind": "ftp"}}); !errors.Is(e, ErrCast) {
+		t.Errorf("failed to reject unknown variant, %v...", e)
+	}
+}
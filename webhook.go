@@ -0,0 +1,91 @@
+package gonf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// The most webhook deliveries, including their retries, that may be in flight
+// at once, beyond which change summaries are dropped with a warning.
+const maxDeliveries = 4
+
+var (
+	post  = (&http.Client{Timeout: 10 * time.Second}).Post
+	sleep = time.Sleep
+)
+
+// The summary sent to a Webhook after a reload that changed the configuration.
+type notification struct {
+	Application string    `json:"application"`
+	File        string    `json:"file"`
+	Fingerprint string    `json:"fingerprint"`
+	Time        time.Time `json:"time"`
+	Changes     ChangeSet `json:"changes"`
+}
+
+func (c *Config) deliver(url string, retries int, data []byte) error {
+	var err error
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			sleep(time.Duration(1<<uint(i-1)) * time.Second)
+		}
+		var resp *http.Response
+		if resp, err = post(url, "application/json", bytes.NewReader(data)); err != nil {
+			continue
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return err
+}
+
+func (c *Config) hook(changes ChangeSet) {
+	c.mu.Lock()
+	url, retries := c.webhook, c.retries
+	if c.deliveries == nil {
+		c.deliveries = make(chan struct{}, maxDeliveries)
+	}
+	deliveries := c.deliveries
+	c.mu.Unlock()
+	if url == "" || len(changes) == 0 {
+		return
+	}
+	data, err := json.Marshal(notification{c.application(), c.ConfigFile(), c.Fingerprint(), now(), changes})
+	if err != nil {
+		return
+	}
+	select {
+	case deliveries <- struct{}{}:
+	default:
+		c.notify(slog.LevelWarn, "dropped notification to %s, %d deliveries are already in flight", url, maxDeliveries)
+		return
+	}
+	go func() {
+		defer func() { <-deliveries }()
+		if err := c.deliver(url, retries, data); err != nil {
+			c.notify(slog.LevelWarn, "failed to notify %s of configuration changes: %v", url, err)
+		}
+	}()
+}
+
+// Send a json summary of the changes, with sensitive values redacted, as a
+// POST to a url after each Reload or Apply that changes the configuration,
+// so that audit systems and chat channels learn of them.  Failed deliveries
+// are retried in the background with an exponential backoff from one second,
+// each attempt times out after ten seconds, and summaries are dropped with a
+// warning while too many deliveries are in flight.  An empty url disables it.
+func (c *Config) Webhook(url string, retries int) {
+	c.mu.Lock()
+	c.webhook, c.retries = url, retries
+	c.mu.Unlock()
+}
@@ -0,0 +1,80 @@
+package gonf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { sleep = time.Sleep }()
+	sleep = func(time.Duration) {}
+	attempts, received := 0, make(chan notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var n notification
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &n)
+		received <- n
+	}))
+	defer server.Close()
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Sensitive("EnvString")
+	c.Webhook(server.URL, 2)
+	c.Apply(map[string]interface{}{})
+	if e := c.Apply(map[string]interface{}{"OptionString": "changed", "EnvString": "secret"}); e != nil {
+		t.Fatalf("failed to apply changes, %v...", e)
+	}
+	select {
+	case n := <-received:
+		if attempts != 3 || len(n.Changes) != 2 || n.Changes[0].Key != "EnvString" || n.Changes[0].New != redacted || n.Changes[1].New != "changed" {
+			t.Errorf("failed to deliver change summary, %d %+v...", attempts, n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to deliver change summary after %d attempts...", attempts)
+	}
+}
+
+func TestWebhookInFlight(t *testing.T) {
+	os.Args = []string{}
+	os.Clearenv()
+	defer func() { post = (&http.Client{Timeout: 10 * time.Second}).Post }()
+	block, posted := make(chan struct{}), make(chan struct{}, maxDeliveries+1)
+	post = func(string, string, io.Reader) (*http.Response, error) {
+		posted <- struct{}{}
+		<-block
+		return nil, errors.New("unreachable")
+	}
+
+	mc := &mockConfig{}
+	c := &Config{}
+	c.Target(mc)
+	c.Webhook("http://localhost", 0)
+	for i := 0; i <= maxDeliveries; i++ {
+		c.hook(ChangeSet{{Key: "OptionString", New: fmt.Sprint(i)}})
+	}
+	for i := 0; i < maxDeliveries; i++ {
+		<-posted
+	}
+	select {
+	case <-posted:
+		t.Error("failed to limit deliveries in flight...")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(block)
+}